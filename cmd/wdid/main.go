@@ -26,15 +26,27 @@ var (
 	bumpID   = bump.Arg("id", "ID of item to bump.").Required().String()
 	bumpTime = bump.Flag("time", "Time to bump item to the item at.").Short('t').PlaceHolder("TIME").Default("now").String()
 
-	add      = app.Command("add", "Add a new task to track.")
-	addTime  = add.Flag("time", "Time to add the task at.").Short('t').PlaceHolder("TIME").Default("now").String()
-	addDone  = add.Flag("done", "Mark task as done already").Short('d').Bool()
-	newThing = add.Arg("new-task", "Description of new task.").String()
+	add         = app.Command("add", "Add a new task, or item of another kind, to track.")
+	addTime     = add.Flag("time", "Time to add the task at.").Short('t').PlaceHolder("TIME").Default("now").String()
+	addDone     = add.Flag("done", "Mark task as done already").Short('d').Bool()
+	addKind     = add.Flag("kind", "Kind of item to add, task, note or one declared in the config.").Short('k').PlaceHolder("NAME").Default("task").String()
+	addDue      = add.Flag("due", "Time the task is due by.").PlaceHolder("TIME").String()
+	addPriority = add.Flag("priority", "Priority of the task, from 1 (high) to 4 (lowest).").Short('p').PlaceHolder("PRIORITY").String()
+	addRecur    = add.Flag("recur", "Rule for the task to recur with, e.g. daily, weekdays, \"weekly mon,thu\", \"monthly 15\" or \"every 3 days\".").Short('r').PlaceHolder("RULE").String()
+	newThing    = add.Arg("new-task", "Description of new task.").String()
 
 	addNote      = app.Command("note", "Add a new note to track.")
 	addNoteTime  = addNote.Flag("time", "Time to add the note at.").Short('t').PlaceHolder("TIME").Default("now").String()
 	newNoteThing = addNote.Arg("new-note", "Summary of new note.").String()
 
+	block   = app.Command("block", "Mark a task as blocked by another, until that one is done or skipped.")
+	blockID = block.Arg("id", "ID of task that is blocked.").Required().String()
+	blockBy = block.Flag("by", "ID of task blocking it.").Required().String()
+
+	check     = app.Command("check", "Tick, or untick, an item in a task's checklist.")
+	checkID   = check.Arg("id", "ID of task with the checklist.").Required().String()
+	checkItem = check.Arg("n", "Which checklist item to tick, counting from 1.").Required().Int()
+
 	do   = app.Command("do", "Mark a task as done.")
 	doID = do.Arg("id", "ID of task to mark done.").Required().String()
 
@@ -52,16 +64,32 @@ var (
 
 	groupList = app.Command("group-ls", "List groups.")
 
+	history   = app.Command("history", "Show every change made to an item.")
+	historyID = history.Arg("id", "ID of item to show history for.").Required().String()
+
 	importCmd      = app.Command("import", "Import items from a file or stdin.")
 	importFilename = importCmd.Arg("in", "Filename to import from, if omitted, stdin used").String()
 
 	list       = app.Command("ls", "List the items you're tracking.").Alias("list").Default()
 	listFilter = list.Flag("filter", "Filter the results").Short('f').String()
 	listGroup  = list.Flag("group", "List items in a group").Short('g').String()
+	listSort   = list.Flag("sort", "Sort by time, status, kind, priority or tag, with a - for descending, e.g. -time").String()
+	listLimit  = list.Flag("limit", "List at most this many items").Int()
+	listOffset = list.Flag("offset", "Skip this many items before listing").Int()
 	listArg    = list.Arg("filters", "Filter your items.").Default("0").String()
 
-	rm   = app.Command("rm", "Remove (permanently!) a single item.").Alias("delete")
-	rmID = rm.Arg("id", "ID of item to remove.").Required().String()
+	recur     = app.Command("recur", "Set the rule a task recurs with.")
+	recurID   = recur.Arg("id", "ID of task to recur.").Required().String()
+	recurRule = recur.Arg("rule", "Rule to recur with, e.g. daily, weekdays, \"weekly mon,thu\", \"monthly 15\", \"every 3 days\" or none to stop.").Required().String()
+
+	recurList = app.Command("recur-ls", "List the rules tasks recur with.")
+
+	restore   = app.Command("restore", "Restore an item from the trash.")
+	restoreID = restore.Arg("id", "ID of item to restore.").Required().String()
+
+	rm      = app.Command("rm", "Move a single item to the trash.").Alias("delete")
+	rmID    = rm.Arg("id", "ID of item to remove.").Required().String()
+	rmForce = rm.Flag("force", "Remove the item permanently, skipping the trash.").Short('f').Bool()
 
 	skip   = app.Command("skip", "Mark a task as skipped.")
 	skipID = skip.Arg("id", "ID of task to mark skipped.").Required().String()
@@ -70,7 +98,29 @@ var (
 	showID        = show.Arg("id", "ID of item to show.").Required().String()
 	showConnected = show.Flag("connected", "Show connected items also.").Short('c').Bool()
 
+	status       = app.Command("status", "Change the status of a task, to done, skipped, waiting or one declared in the config.")
+	statusID     = status.Arg("id", "ID of task to change.").Required().String()
+	statusStatus = status.Arg("status", "Status to change it to.").Required().String()
+
+	start   = app.Command("start", "Start tracking time against a task, stopping any other.")
+	startID = start.Arg("id", "ID of task to track.").Required().String()
+
+	stop   = app.Command("stop", "Stop tracking time.")
+	stopID = stop.Arg("id", "ID of task to stop tracking, if omitted, everything is stopped.").String()
+
 	tagList = app.Command("tag-ls", "List tags.")
+
+	timeReport       = app.Command("time-report", "Sum the time tracked against items, by day and by tag.")
+	timeReportFilter = timeReport.Arg("filters", "Filter the items to report on, where a time is when the time was tracked.").Default("0").String()
+
+	trashList = app.Command("trash-ls", "List items in the trash.")
+
+	trashEmpty          = app.Command("trash-empty", "Remove (permanently!) items in the trash.")
+	trashEmptyOlderThan = trashEmpty.Flag("older-than", "Only remove items trashed before this time.").PlaceHolder("TIME").String()
+
+	undo      = app.Command("undo", "Undo the last changes made.")
+	undoCount = undo.Arg("count", "How many commands to undo.").Default("1").Int()
+	undoList  = undo.Flag("list", "List the commands that can be undone, most recent first.").Short('l').Bool()
 )
 
 func main() {
@@ -86,13 +136,25 @@ func main() {
 
 	commandName := kingpin.MustParse(app.Parse(os.Args[1:]))
 
+	kinds, err := core.NewKinds(conf.Kinds)
+	app.FatalIfError(err, "")
+	statuses, err := core.NewStatuses(conf.Statuses)
+	app.FatalIfError(err, "")
+
 	store, err := createStore(conf)
 	app.FatalIfError(err, "")
+	app.FatalIfError(store.Open(), "")
+	if commandName != undo.FullCommand() {
+		// record what the command changes, so it can be undone later
+		store = core.NewJournal(store, strings.Join(os.Args[1:], " "))
+	}
 
 	ctx := context.WithValue(context.Background(), "store", store)
 	ctx = context.WithValue(ctx, "verbose", *v)
 	ctx = context.WithValue(ctx, "format", *format)
 	ctx = context.WithValue(ctx, "config", conf)
+	ctx = context.WithValue(ctx, "kinds", kinds)
+	ctx = context.WithValue(ctx, "statuses", statuses)
 
 	switch commandName {
 	case add.FullCommand():
@@ -106,11 +168,7 @@ func main() {
 				break
 			}
 		}
-		if *addDone {
-			err = core.AddDone(ctx, description, *addTime)
-		} else {
-			err = core.Add(ctx, description, *addTime)
-		}
+		err = core.AddKind(ctx, *addKind, description, *addTime, *addDue, *addPriority, *addRecur, *addDone)
 	case addNote.FullCommand():
 		var description io.Reader
 		description, err = fileedit.EditExisting(*newNoteThing)
@@ -120,6 +178,10 @@ func main() {
 		err = core.AddNote(ctx, description, *addNoteTime)
 	case bump.FullCommand():
 		err = core.Bump(ctx, *bumpID, *bumpTime)
+	case block.FullCommand():
+		err = core.Block(ctx, *blockID, *blockBy)
+	case check.FullCommand():
+		err = core.Check(ctx, *checkID, *checkItem)
 	case do.FullCommand():
 		err = core.Do(ctx, *doID)
 	case edit.FullCommand():
@@ -128,21 +190,47 @@ func main() {
 		} else {
 			err = core.Edit(ctx, *editID, strings.NewReader(*editDescription), *editTime)
 		}
+	case history.FullCommand():
+		err = core.History(ctx, *historyID)
 	case importCmd.FullCommand():
 		err = core.Import(ctx, *importFilename)
 	case list.FullCommand():
 		if *listFilter != "" {
 			*listArg = *listFilter // temporary override
 		}
-		err = core.List(ctx, *listArg, *listGroup)
+		err = core.List(ctx, *listArg, *listGroup, core.ListOptions{Sort: *listSort, Limit: *listLimit, Offset: *listOffset})
+	case recur.FullCommand():
+		err = core.Recur(ctx, *recurID, *recurRule)
+	case recurList.FullCommand():
+		err = core.ListRecurring(ctx)
 	case rm.FullCommand():
-		err = core.Rm(ctx, *rmID)
+		err = core.Rm(ctx, *rmID, *rmForce)
+	case restore.FullCommand():
+		err = core.Restore(ctx, *restoreID)
+	case status.FullCommand():
+		err = core.ChangeStatus(ctx, *statusID, *statusStatus)
 	case skip.FullCommand():
 		err = core.Skip(ctx, *skipID)
 	case show.FullCommand():
 		err = core.Show(ctx, *showID, *showConnected)
+	case start.FullCommand():
+		err = core.Start(ctx, *startID)
+	case stop.FullCommand():
+		err = core.Stop(ctx, *stopID)
 	case tagList.FullCommand():
 		err = core.ListTag(ctx)
+	case timeReport.FullCommand():
+		err = core.TimeReport(ctx, *timeReportFilter)
+	case undo.FullCommand():
+		if *undoList {
+			err = core.ListOperations(ctx)
+		} else {
+			err = core.Undo(ctx, *undoCount)
+		}
+	case trashList.FullCommand():
+		err = core.ListTrash(ctx)
+	case trashEmpty.FullCommand():
+		err = core.EmptyTrash(ctx, *trashEmptyOlderThan)
 	case group.FullCommand():
 		err = core.CreateGroup(ctx, *groupName, *groupFilters)
 	case groupRm.FullCommand():
@@ -150,13 +238,19 @@ func main() {
 	case groupList.FullCommand():
 		err = core.ListGroup(ctx)
 	}
+	closeErr := store.Close()
 	app.FatalIfError(err, "")
+	app.FatalIfError(closeErr, "")
 }
 
 func createStore(conf *config.Config) (core.Store, error) {
 	switch conf.Store.Type {
 	case "bolt":
 		return core.NewBoltStore(conf.Store.Filepath())
+	case "sqlite":
+		return core.NewSQLiteStore(conf.Store.Filepath())
+	case "memory":
+		return core.NewMemoryStore(), nil
 	default:
 		return nil, errors.New("store not specified correctly")
 	}
@@ -18,6 +18,7 @@ var (
 	app         = kingpin.New("wdid_migrate", "migrations for wdid")
 	addKinds    = app.Command("add_kinds", "Add kind to items. Items tagged #note should be Notes")
 	addKindsArg = addKinds.Arg("from", "When should migration apply from?").Default("9000").String()
+	indexTags   = app.Command("index_tags", "Add items saved by older versions to the tag, metadata and text search indexes")
 )
 
 func main() {
@@ -35,6 +36,8 @@ func main() {
 
 	store, err := createStore(conf)
 	app.FatalIfError(err, "")
+	app.FatalIfError(store.Open(), "")
+	defer store.Close()
 
 	ctx := context.WithValue(context.Background(), "store", store)
 	ctx = context.WithValue(ctx, "config", conf)
@@ -42,6 +45,8 @@ func main() {
 	switch commandName {
 	case addKinds.FullCommand():
 		migrations.AddKinds(ctx, *addKindsArg)
+	case indexTags.FullCommand():
+		migrations.IndexTags(ctx)
 	}
 }
 
@@ -49,6 +54,10 @@ func createStore(conf *config.Config) (core.Store, error) {
 	switch conf.Store.Type {
 	case "bolt":
 		return core.NewBoltStore(conf.Store.Filepath())
+	case "sqlite":
+		return core.NewSQLiteStore(conf.Store.Filepath())
+	case "memory":
+		return core.NewMemoryStore(), nil
 	default:
 		return nil, errors.New("store not specified correctly")
 	}
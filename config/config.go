@@ -16,15 +16,57 @@ type ConfigStore struct {
 	File string
 }
 
+type ConfigChecklist struct {
+	AutoComplete bool `toml:"auto_complete"`
+}
+
+// ConfigKind declares a kind of item, as well as the built in task and note
+type ConfigKind struct {
+	Name   string
+	Status bool   // has a status like a task, rather than being statusless like a note
+	Glyph  string // shown before the ID when listing
+	Color  string // one of black, red, green, yellow, blue, magenta, cyan or white
+}
+
+// ConfigStatus declares a status items can be in, as well as the built in ones
+type ConfigStatus struct {
+	Name  string
+	Glyph string   // shown before the ID when listing
+	Color string   // one of black, red, green, yellow, blue, magenta, cyan or white
+	From  []string // the statuses items can change to this one from, just waiting if none are given
+}
+
 type Config struct {
-	Store  ConfigStore
-	Editor string
+	Store     ConfigStore
+	Editor    string
+	Checklist ConfigChecklist
+	Kinds     []ConfigKind   `toml:"kind"`
+	Statuses  []ConfigStatus `toml:"status"`
 }
 
 var defaultConfig = `
 [store]
+# one of "bolt", "sqlite" or "memory" (nothing is kept between runs)
 type = "bolt"
 file = "~/.config/wdid/wdid.db"
+
+[checklist]
+# mark a task done once every item in its checklist is ticked
+auto_complete = false
+
+# declare kinds of item beyond task and note, added with "wdid add --kind NAME"
+# [[kind]]
+# name = "meeting"
+# status = true # has a status like a task, or is statusless like a note
+# glyph = "◷"
+# color = "magenta"
+
+# declare statuses beyond waiting, done and skipped, changed to with "wdid status <id> <status>"
+# [[status]]
+# name = "in-progress"
+# glyph = "◐"
+# color = "cyan"
+# from = ["waiting", "review"] # statuses items can change to this one from
 `
 
 func Load() (*Config, error) {
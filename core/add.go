@@ -4,34 +4,54 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 )
 
 func Add(ctx context.Context, description io.Reader, timeString string) error {
-	itemCreator := &ItemCreator{ctx: ctx}
-	item, err := addCreate(description, timeString, itemCreator.CreateTask)
+	return AddTask(ctx, description, timeString, "", "", "", false)
+}
+
+func AddDone(ctx context.Context, description io.Reader, timeString string) error {
+	return AddTask(ctx, description, timeString, "", "", "", true)
+}
+
+// AddTask adds a task, due at dueString, with priorityString and recurring with recurString if they
+// aren't empty, and already done if done is set
+func AddTask(ctx context.Context, description io.Reader, timeString string, dueString string, priorityString string, recurString string, done bool) error {
+	return AddKind(ctx, Task.String(), description, timeString, dueString, priorityString, recurString, done)
+}
+
+// AddKind adds an item of the kind named, like AddTask, though only kinds with a status can be
+// due, have a priority, recur or be done
+func AddKind(ctx context.Context, kindName string, description io.Reader, timeString string, dueString string, priorityString string, recurString string, done bool) error {
+	definition, err := kindsFromContext(ctx).Find(kindName)
 	if err != nil {
 		return err
 	}
+	if done && definition.Kind != Task {
+		return fmt.Errorf("%s has no status, so can't be done", definition.Name)
+	}
 
-	NewItemPrinter(ctx).Print(item)
-	return nil
-}
-
-func AddDone(ctx context.Context, description io.Reader, timeString string) error {
 	itemCreator := &ItemCreator{ctx: ctx}
-	item, err := addCreate(description, timeString, itemCreator.CreateTask)
+	item, err := addCreate(description, TimeParser{Input: timeString}, func(data string, at time.Time) (*Item, error) {
+		return itemCreator.CreateKindWith(definition, data, at, dueString, priorityString, recurString)
+	})
 	if err != nil {
 		return err
 	}
-	Do(ctx, item.ID())
+
+	if done {
+		return Do(ctx, item.ID())
+	}
+	NewItemPrinter(ctx).Print(item)
 	return nil
 }
 
 func AddNote(ctx context.Context, description io.Reader, timeString string) error {
 	itemCreator := &ItemCreator{ctx: ctx}
-	item, err := addCreate(description, timeString, itemCreator.CreateNote)
+	item, err := addCreate(description, TimeParser{Input: timeString}, itemCreator.CreateNote)
 	if err != nil {
 		return err
 	}
@@ -41,8 +61,8 @@ func AddNote(ctx context.Context, description io.Reader, timeString string) erro
 
 type itemCreateFn func(data string, at time.Time) (*Item, error)
 
-func addCreate(description io.Reader, timeString string, creator itemCreateFn) (*Item, error) {
-	at, err := TimeParser{Input: timeString}.Parse()
+func addCreate(description io.Reader, timeParser TimeParser, creator itemCreateFn) (*Item, error) {
+	at, err := timeParser.Parse()
 	if err != nil {
 		return nil, err
 	}
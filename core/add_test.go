@@ -34,6 +34,19 @@ func TestAddFutureItem(t *testing.T) {
 	})
 }
 
+func TestAddAtTimeOfDay(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		ref := timeAt("2018-03-23 16:30:00 -0400 EDT")
+		itemCreator := &ItemCreator{ctx: ctx}
+		item, err := addCreate(strings.NewReader("standup"), TimeParser{Input: "tomorrow 9am", startTime: ref}, itemCreator.CreateTask)
+		assert.NilError(t, err)
+		found, err := store.FindAll(item.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, found[0].Data(), "standup")
+		assert.Equal(t, found[0].Time().Unix(), timeAt("2018-03-24 09:00:00 -0400 EDT").Unix())
+	})
+}
+
 func TestAddDone(t *testing.T) {
 	contextWithStore(func(ctx context.Context, store Store) {
 		err := AddDone(ctx, strings.NewReader("my new item"), "now")
@@ -45,6 +58,59 @@ func TestAddDone(t *testing.T) {
 	})
 }
 
+func TestAddTaskDue(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		err := AddTask(ctx, strings.NewReader("my new item"), "now", "2025-01-01", "", "", false)
+		assert.NilError(t, err)
+		item := mostRecentItem(store)
+		timespan, _ := TimeParser{Input: "2025-01-01"}.Parse()
+		assert.Equal(t, item.Due().Unix(), timespan.End.Unix())
+	})
+}
+
+func TestAddInlineDue(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		err := Add(ctx, strings.NewReader("file taxes due:2025-01-01 #admin"), "now")
+		assert.NilError(t, err)
+		item := mostRecentItem(store)
+		assert.Equal(t, item.Data(), "file taxes #admin")
+		assert.Assert(t, !item.Due().IsZero())
+		assert.Assert(t, item.Overdue())
+	})
+}
+
+func TestAddTaskPriority(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		err := AddTask(ctx, strings.NewReader("my new item"), "now", "", "high", "", false)
+		assert.NilError(t, err)
+		item := mostRecentItem(store)
+		assert.Equal(t, item.Priority(), HighPriority)
+
+		err = AddTask(ctx, strings.NewReader("my new item"), "now", "", "urgent", "", false)
+		assert.Error(t, err, "priority \"urgent\" not found")
+	})
+}
+
+func TestAddInlinePriority(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		err := Add(ctx, strings.NewReader("fix the build !2 #work"), "now")
+		assert.NilError(t, err)
+		item := mostRecentItem(store)
+		assert.Equal(t, item.Data(), "fix the build #work")
+		assert.Equal(t, item.Priority(), MediumPriority)
+	})
+}
+
+func TestAddNoteIgnoresInlineDue(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		err := AddNote(ctx, strings.NewReader("moved due:2025-01-01"), "now")
+		assert.NilError(t, err)
+		item := mostRecentItem(store)
+		assert.Equal(t, item.Data(), "moved due:2025-01-01")
+		assert.Assert(t, item.Due().IsZero())
+	})
+}
+
 func contextWithStore(f func(ctx context.Context, store Store)) {
 	ctx := context.Background()
 
@@ -53,9 +119,7 @@ func contextWithStore(f func(ctx context.Context, store Store)) {
 		os.Exit(1)
 	}
 
-	store.DropBucket("StormItem")
-	store.DropBucket("StormTag")
-	store.DropBucket("StormGroup")
+	store.DropBuckets()
 
 	ctx = context.WithValue(ctx, "store", store)
 	f(ctx, store)
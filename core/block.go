@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+)
+
+// blockers knows which tasks block the items it was loaded for, and whether they're still open
+type blockers struct {
+	open   map[string]bool     // ids of the tasks named by [[blocked-by:<id>]], to whether they're still open
+	blocks map[string][]string // ids of items blocked by open items with [[blocks:<id>]], to the ids blocking them
+}
+
+// loadBlockers looks up what blocks items, or every task with a [[blocked-by:<id>]] connection if
+// items is nil. Open tasks with [[blocks:<id>]] connections are found through the connection index,
+// and the tasks named by [[blocked-by:<id>]] connections are found by id, so it never loads every item.
+func loadBlockers(store Store, items []*Item) (*blockers, error) {
+	b := &blockers{open: map[string]bool{}, blocks: map[string][]string{}}
+	blocking, err := store.ListFilters([]filter.Filter{openStatusFilter(), NewConnectionFilter(parser.BlocksConnection)})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range blocking {
+		for _, id := range item.Blocks() {
+			b.blocks[id] = append(b.blocks[id], item.ID())
+		}
+	}
+
+	if items == nil {
+		items, err = store.ListFilters([]filter.Filter{NewConnectionFilter(parser.BlockedByConnection)})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range items {
+		for _, blockerID := range item.BlockedBy() {
+			if _, ok := b.open[blockerID]; ok {
+				continue
+			}
+			b.open[blockerID], err = isOpen(store, blockerID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// isOpen is true if there's a task with exactly id that hasn't been finished
+func isOpen(store Store, id string) (bool, error) {
+	found, err := store.FindAll(id)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, item := range found {
+		if item.ID() == id {
+			return openStatusFilter().Match(MatchableItem{Item: item})
+		}
+	}
+	return false, nil
+}
+
+// blocking is the ids of the open items blocking the item with id and connections
+func (b *blockers) blocking(id string, connections []string) []string {
+	found := append([]string{}, b.blocks[id]...)
+	for _, connection := range connections {
+		connectionType, blockerID := parser.SplitConnection(connection)
+		if connectionType == parser.BlockedByConnection && b.open[blockerID] {
+			found = append(found, blockerID)
+		}
+	}
+	return found
+}
+
+// Block marks a task as blocked by another, adding a [[blocked-by:<id>]] connection to it
+func Block(ctx context.Context, id string, byID string) error {
+	item, err := FindOneOrPrint(ctx, id)
+	if err != nil {
+		return err
+	}
+	blocker, err := FindOneOrPrint(ctx, byID)
+	if err != nil {
+		return err
+	}
+	if item.ID() == blocker.ID() {
+		return errors.New("a task can't block itself")
+	}
+	for _, blockerID := range item.BlockedBy() {
+		if blockerID == blocker.ID() {
+			return fmt.Errorf("%s is already blocked by %s", item.ID(), blocker.ID())
+		}
+	}
+
+	data := fmt.Sprintf("%s [[%s:%s]]", item.Data(), parser.BlockedByConnection, blocker.ID())
+	return Edit(ctx, item.ID(), strings.NewReader(data), "")
+}
+
+// warnIfBlocked prints a warning if any of the tasks blocking item are still open
+func warnIfBlocked(ctx context.Context, item *Item) {
+	store := ctx.Value("store").(Store)
+	b, err := loadBlockers(store, []*Item{item})
+	if err != nil {
+		return
+	}
+	blocking := b.blocking(item.ID(), item.Connections())
+	if len(blocking) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s is blocked by %s, still open\n", item.ID(), strings.Join(blocking, ", "))
+	}
+}
+
+type BlockedFilter struct {
+	comparison filter.FilterComparison
+	blocked    bool
+	blockers   *blockers
+}
+
+func NewBlockedFilter(store Store, comparison filter.FilterComparison, blocked bool) (*BlockedFilter, error) {
+	b, err := loadBlockers(store, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockedFilter{comparison: comparison, blocked: blocked, blockers: b}, nil
+}
+
+// BlockedFilterFn matches tasks blocked by another task still open with blocked=true,
+// and those that aren't with blocked=false
+func BlockedFilterFn(store Store) parser.ToFilterFn {
+	return func(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+		switch comparison {
+		case filter.FilterGt, filter.FilterLt:
+			return nil, errors.New("blocked filter does not support > or <")
+		case filter.FilterLike:
+			return nil, errors.New("blocked filter does not support ~")
+		}
+		switch val {
+		case "true":
+			return NewBlockedFilter(store, comparison, true)
+		case "false":
+			return NewBlockedFilter(store, comparison, false)
+		}
+		return nil, fmt.Errorf("blocked filter must be true or false, not %q", val)
+	}
+}
+
+func (blockedFilter *BlockedFilter) Match(matchable filter.Matchable) (bool, error) {
+	blocked := len(blockedFilter.blockers.blocking(matchable.ID(), matchable.Connections())) > 0
+	switch blockedFilter.comparison {
+	case filter.FilterEq:
+		return blocked == blockedFilter.blocked, nil
+	case filter.FilterNe:
+		return blocked != blockedFilter.blocked, nil
+	}
+	return false, errors.New("unrecognized comparison")
+}
+
+func (blockedFilter *BlockedFilter) String() string {
+	return fmt.Sprintf("Blocked %v %v", blockedFilter.comparison, blockedFilter.blocked)
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestBlock(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("write the release notes"), "now")
+		blocker := mostRecentItem(store)
+		Add(ctx, strings.NewReader("ship the release"), "now")
+		blocked := mostRecentItem(store)
+
+		err := Block(ctx, blocked.ID(), blocker.ID())
+		assert.NilError(t, err)
+		items, _ := store.FindAll(blocked.ID())
+		assert.DeepEqual(t, items[0].BlockedBy(), []string{blocker.ID()})
+
+		err = Block(ctx, blocked.ID(), blocker.ID())
+		assert.Error(t, err, fmt.Sprintf("%s is already blocked by %s", blocked.ID(), blocker.ID()))
+		err = Block(ctx, blocked.ID(), blocked.ID())
+		assert.Error(t, err, "a task can't block itself")
+	})
+}
+
+func TestBlockedFilter(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("write the release notes"), "now")
+		blocker := mostRecentItem(store)
+		Add(ctx, strings.NewReader("ship the release"), "now")
+		blocked := mostRecentItem(store)
+		Add(ctx, strings.NewReader(fmt.Sprintf("tag the release [[blocks:%s]]", blocked.ID())), "now")
+		reverseBlocker := mostRecentItem(store)
+		Block(ctx, blocked.ID(), blocker.ID())
+
+		items, err := ListWithoutPrinting(ctx, "blocked=true")
+		assert.NilError(t, err)
+		assert.Equal(t, len(items), 1)
+		assert.Equal(t, items[0].ID(), blocked.ID())
+
+		items, err = ListWithoutPrinting(ctx, "blocked=false")
+		assert.NilError(t, err)
+		assert.Equal(t, len(items), 2)
+
+		// still blocked until both blockers are finished
+		Do(ctx, blocker.ID())
+		items, _ = ListWithoutPrinting(ctx, "blocked=true")
+		assert.Equal(t, len(items), 1)
+
+		Skip(ctx, reverseBlocker.ID())
+		items, _ = ListWithoutPrinting(ctx, "blocked=true")
+		assert.Equal(t, len(items), 0)
+	})
+}
+
+func TestBlockedFilterFunctionError(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		_, err := ListWithoutPrinting(ctx, "blocked=maybe")
+		assert.Error(t, err, "blocked filter must be true or false, not \"maybe\" at position 9:\nblocked=maybe\n        ^")
+		_, err = ListWithoutPrinting(ctx, "blocked~true")
+		assert.Error(t, err, "blocked filter does not support ~ at position 9:\nblocked~true\n        ^")
+	})
+}
+
+func TestDoBlocked(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("write the release notes"), "now")
+		blocker := mostRecentItem(store)
+		Add(ctx, strings.NewReader(fmt.Sprintf("ship the release [[blocked-by:%s]]", blocker.ID())), "now")
+		blocked := mostRecentItem(store)
+
+		// only warns, the task is still done
+		err := Do(ctx, blocked.ID())
+		assert.NilError(t, err)
+		items, _ := store.FindAll(blocked.ID())
+		assert.Equal(t, items[0].Status(), DoneStatus)
+	})
+}
+
+func TestLoadBlockersForItems(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("write the release notes"), "now")
+		blocker := mostRecentItem(store)
+		Add(ctx, strings.NewReader("book the venue"), "now")
+		otherBlocker := mostRecentItem(store)
+		Add(ctx, strings.NewReader(fmt.Sprintf("ship the release [[blocked-by:%s]]", blocker.ID())), "now")
+		blocked := mostRecentItem(store)
+		Add(ctx, strings.NewReader(fmt.Sprintf("hold the launch party [[blocked-by:%s]] [[blocked-by:gone00]]", otherBlocker.ID())), "now")
+		otherBlocked := mostRecentItem(store)
+
+		// only the blockers of the items asked about are looked up
+		b, err := loadBlockers(store, []*Item{blocked})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, b.open, map[string]bool{blocker.ID(): true})
+		assert.DeepEqual(t, b.blocking(blocked.ID(), blocked.Connections()), []string{blocker.ID()})
+
+		b, err = loadBlockers(store, nil)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, b.open, map[string]bool{blocker.ID(): true, otherBlocker.ID(): true, "gone00": false})
+		assert.DeepEqual(t, b.blocking(otherBlocked.ID(), otherBlocked.Connections()), []string{otherBlocker.ID()})
+	})
+}
@@ -3,15 +3,15 @@ package core
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 func Bump(ctx context.Context, id string, timeString string) error {
-	store := ctx.Value("store").(Store)
 	item, err := FindOneOrPrint(ctx, id)
 	if err != nil {
 		return err
 	}
-	if item.Status() != WaitingStatus {
+	if !item.Open() {
 		return errors.New("can't bump finished item")
 	}
 
@@ -20,16 +20,34 @@ func Bump(ctx context.Context, id string, timeString string) error {
 		return err
 	}
 
+	before := *item
 	newItem := item.Bump(to.Start) // mark old item as done
 
-	// save old
-	err = store.WithContext(ctx).Save(item)
+	// save both or neither, so the old item never points at a missing new one
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+
+		// save old
+		err := store.WithContext(ctx).Save(item)
+		if err != nil {
+			return err
+		}
+		err = saveRevisions(store, &before, item)
+		if err != nil {
+			return err
+		}
+
+		err = stopTracking(store, item.ID(), time.Now())
+		if err != nil {
+			return err
+		}
+
+		// save new
+		return store.WithContext(ctx).Save(newItem)
+	})
 	if err != nil {
 		return err
 	}
-
-	// save new
-	err = store.WithContext(ctx).Save(newItem)
 	NewItemPrinter(ctx).Print(newItem)
-	return err
+	return nil
 }
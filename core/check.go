@@ -0,0 +1,42 @@
+package core
+
+import (
+	"context"
+
+	"github.com/josler/wdid/config"
+	"github.com/josler/wdid/parser"
+)
+
+// Check ticks, or unticks, the nth checklist item of a task. With the checklist auto complete
+// setting on, the task is done once every item is ticked.
+func Check(ctx context.Context, id string, n int) error {
+	item, err := FindOneOrPrint(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tokenizer := &parser.Tokenizer{}
+	data, err := tokenizer.ToggleChecklistItem(item.Data(), n)
+	if err != nil {
+		return err
+	}
+
+	before := *item
+	item.data = data
+	item.touch()
+	checked, total := item.ChecklistProgress()
+	if checked == total && item.Open() && autoCompleteChecklists(ctx) {
+		item.Do()
+	}
+	next, err := saveFinished(ctx, &before, item)
+	NewItemPrinter(ctx).Print(item)
+	if next != nil {
+		NewItemPrinter(ctx).Print(next)
+	}
+	return err
+}
+
+func autoCompleteChecklists(ctx context.Context) bool {
+	conf, ok := ctx.Value("config").(*config.Config)
+	return ok && conf.Checklist.AutoComplete
+}
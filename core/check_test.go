@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/josler/wdid/config"
+	"gotest.tools/assert"
+)
+
+func TestCheck(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("release v2\n- [ ] tag it\n- [ ] write notes"), "now")
+		found := mostRecentItem(store)
+
+		err := Check(ctx, found.ID(), 2)
+		assert.NilError(t, err)
+		items, _ := store.FindAll(found.ID())
+		checked, total := items[0].ChecklistProgress()
+		assert.Equal(t, checked, 1)
+		assert.Equal(t, total, 2)
+
+		err = Check(ctx, found.ID(), 1)
+		assert.NilError(t, err)
+		items, _ = store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), WaitingStatus) // not auto completed by default
+
+		err = Check(ctx, found.ID(), 3)
+		assert.Error(t, err, "checklist item 3 not found, there are 2")
+	})
+}
+
+func TestCheckAutoComplete(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		ctx = context.WithValue(ctx, "config", &config.Config{Checklist: config.ConfigChecklist{AutoComplete: true}})
+		Add(ctx, strings.NewReader("release v2\n- [x] tag it\n- [ ] write notes"), "now")
+		found := mostRecentItem(store)
+
+		err := Check(ctx, found.ID(), 2)
+		assert.NilError(t, err)
+		items, _ := store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), DoneStatus)
+	})
+}
@@ -10,9 +10,13 @@ func Do(ctx context.Context, id string) error {
 		return err
 	}
 
+	warnIfBlocked(ctx, item)
+	before := *item
 	item.Do()
-	store := ctx.Value("store").(Store)
-	err = store.WithContext(ctx).Save(item)
+	next, err := saveFinished(ctx, &before, item)
 	NewItemPrinter(ctx).Print(item)
+	if next != nil {
+		NewItemPrinter(ctx).Print(next)
+	}
 	return err
 }
@@ -25,3 +25,71 @@ func TestDoMultiMatching(t *testing.T) {
 		assert.Error(t, err, "unable to find unique item", "Do didn't error as it should")
 	})
 }
+
+func TestDoCompletedAt(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		err := Do(ctx, found.ID())
+		assert.NilError(t, err)
+		done, _ := FindOneOrPrint(ctx, found.ID())
+		assert.Assert(t, time.Since(done.CompletedAt()) < time.Minute)
+
+		items, err := ListWithoutPrinting(ctx, "completed=today")
+		assert.NilError(t, err)
+		assert.Equal(t, len(items), 1)
+	})
+}
+
+func TestDoRecurring(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("weekly report #admin recur:weekly"), "now")
+		found := mostRecentItem(store)
+		assert.Equal(t, found.Data(), "weekly report #admin")
+
+		err := Do(ctx, found.ID())
+		assert.NilError(t, err)
+
+		items, _ := store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), DoneStatus)
+		nextItems, err := store.FindAll(items[0].NextID())
+		assert.NilError(t, err)
+		assert.Equal(t, nextItems[0].Status(), WaitingStatus)
+		assert.Equal(t, nextItems[0].Recurrence(), "weekly")
+		assert.Equal(t, nextItems[0].PreviousID(), found.ID())
+		assert.Equal(t, nextItems[0].Time().Unix(), found.Time().AddDate(0, 0, 7).Unix())
+	})
+}
+
+func TestSkipRecurring(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("water plants"), "now")
+		found := mostRecentItem(store)
+		err := Recur(ctx, found.ID(), "every 3 days")
+		assert.NilError(t, err)
+
+		err = Skip(ctx, found.ID())
+		assert.NilError(t, err)
+		items, _ := store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), SkippedStatus)
+		assert.Assert(t, items[0].NextID() != "")
+
+		// stopping recurrence means finishing doesn't create another
+		err = Recur(ctx, items[0].NextID(), "none")
+		assert.NilError(t, err)
+		err = Do(ctx, items[0].NextID())
+		assert.NilError(t, err)
+		nextItems, _ := store.FindAll(items[0].NextID())
+		assert.Equal(t, nextItems[0].NextID(), "")
+	})
+}
+
+func TestRecurError(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		AddNote(ctx, strings.NewReader("my note"), "now")
+		found := mostRecentItem(store)
+		err := Recur(ctx, found.ID(), "daily")
+		assert.Error(t, err, "only tasks can recur, "+found.ID()+" is a note")
+	})
+}
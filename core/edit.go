@@ -21,8 +21,15 @@ func Edit(ctx context.Context, idString string, description io.Reader, timeStrin
 	stringDescription := buf.String()
 	stringDescription = strings.Trim(stringDescription, "\n")
 
-	itemCreator := &ItemCreator{ctx: ctx}
-	item, err = itemCreator.Edit(item, stringDescription, timeString)
+	before := *item
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		itemCreator := &ItemCreator{ctx: ctx}
+		item, err = itemCreator.Edit(item, stringDescription, timeString)
+		if err != nil {
+			return err
+		}
+		return saveRevisions(ctx.Value("store").(Store), &before, item)
+	})
 	if err != nil {
 		return err
 	}
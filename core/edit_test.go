@@ -57,3 +57,18 @@ func TestEditTrimsNewlines(t *testing.T) {
 		assert.Equal(t, found.Data(), "change the message", "doesn't trim newlines correctly")
 	})
 }
+
+func TestEditKeepsCreatedAt(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+		assert.Assert(t, !found.CreatedAt().IsZero())
+
+		Edit(ctx, found.ID(), strings.NewReader("change the message"), "2018-04-07")
+		edited, err := FindOneOrPrint(ctx, found.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, edited.CreatedAt().Unix(), found.CreatedAt().Unix())
+		assert.Assert(t, !edited.UpdatedAt().Before(found.UpdatedAt()))
+		assert.Assert(t, edited.CompletedAt().IsZero())
+	})
+}
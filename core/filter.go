@@ -3,52 +3,103 @@ package core
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/josler/wdid/filter"
 	"github.com/josler/wdid/parser"
 )
 
-func DefaultParser(store Store) *parser.Parser {
+func DefaultParser(store Store, kinds Kinds, statuses Statuses) *parser.Parser {
 	p := &parser.Parser{}
 	p.RegisterToFilter("tag", TagFilterFn(store))
-	p.RegisterToFilter("status", StatusFilterFn)
+	p.RegisterToFilter("status", StatusFilterFn(statuses))
 	p.RegisterToFilter("time", DateFilterFn)
-	p.RegisterToFilter("group", GroupFilterFn(store))
-	p.RegisterToFilter("kind", KindFilterFn)
+	p.RegisterToFilter("group", GroupFilterFn(store, kinds, statuses))
+	p.RegisterToFilter("kind", KindFilterFn(kinds))
+	p.RegisterToFilter("text", TextFilterFn)
+	p.RegisterToFilter("due", DueFilterFn)
+	p.RegisterToFilter("priority", PriorityFilterFn)
+	p.RegisterToFilter("blocked", BlockedFilterFn(store))
+	p.RegisterToFilter(CreatedTimestamp, TimestampFilterFn(CreatedTimestamp))
+	p.RegisterToFilter(UpdatedTimestamp, TimestampFilterFn(UpdatedTimestamp))
+	p.RegisterToFilter(CompletedTimestamp, TimestampFilterFn(CompletedTimestamp))
+	p.RegisterToFilterFamily("meta.", MetaFilterFn)
+	p.RegisterToFilter("sort", SortFilterFn)
+	p.RegisterToFilter("limit", LimitFilterFn)
+	p.RegisterToFilter("offset", OffsetFilterFn)
 	return p
 }
 
+// parseFilters parses a filter string with the default parser, checking that sort, limit and
+// offset are only used where they can apply
+func parseFilters(store Store, kinds Kinds, statuses Statuses, filterString string) ([]filter.Filter, error) {
+	filters, err := DefaultParser(store, kinds, statuses).Parse(filterString)
+	if err != nil {
+		return nil, err
+	}
+	err = checkListOptions(filters)
+	if err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
 type DateFilter struct {
-	timespan *Timespan
+	comparison filter.FilterComparison
+	timespan   *Timespan
 }
 
 func NewDateFilter(comparison filter.FilterComparison, timespan *Timespan) *DateFilter {
-	return &DateFilter{timespan: timespan}
+	return &DateFilter{comparison: comparison, timespan: timespan}
 }
 
+// DateFilterFn matches items in a time, or a range of times like "2024-03-03..2024-03-17" where
+// either end can be left open. With != it matches items outside them instead.
 func DateFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
-	from, err := TimeParser{Input: val}.Parse()
+	from, err := timeFilterSpan("date", comparison, val)
+	if err != nil {
+		return nil, err
+	}
+	return NewDateFilter(comparison, from), nil
+}
+
+// timeFilterSpan parses the time or range of times for the filter named name, which > and <
+// open out to everything after or before the time
+func timeFilterSpan(name string, comparison filter.FilterComparison, val string) (*Timespan, error) {
+	timespan, err := TimeParser{Input: val}.ParseRange()
 	if err != nil {
 		return nil, err
 	}
 	switch comparison {
-	case filter.FilterGt:
-		from.End = Timespan{}.LatestTime()
-	case filter.FilterLt:
-		from.Start = Timespan{}.EarliestTime()
-	case filter.FilterNe:
-		return nil, errors.New("date filter does not support comparison 'ne'")
+	case filter.FilterGt, filter.FilterLt:
+		if isTimeRange(val) {
+			return nil, fmt.Errorf("%s filter does not support > or < with a range", name)
+		}
+		if comparison == filter.FilterGt {
+			timespan.End = Timespan{}.LatestTime()
+		} else {
+			timespan.Start = Timespan{}.EarliestTime()
+		}
+	case filter.FilterLike:
+		return nil, fmt.Errorf("%s filter does not support comparison ~", name)
 	}
-
-	return NewDateFilter(comparison, from), nil
+	return timespan, nil
 }
 
 func (dateFilter *DateFilter) Match(matchable filter.Matchable) (bool, error) {
-	return (matchable.Datetime() >= dateFilter.timespan.Start.Unix() && matchable.Datetime() <= dateFilter.timespan.End.Unix()), nil
+	within := matchable.Datetime() >= dateFilter.timespan.Start.Unix() && matchable.Datetime() <= dateFilter.timespan.End.Unix()
+	if dateFilter.comparison == filter.FilterNe {
+		return !within, nil
+	}
+	return within, nil
 }
 
 func (dateFilter *DateFilter) String() string {
+	if dateFilter.comparison == filter.FilterNe {
+		return fmt.Sprintf("Not between %v and %v", dateFilter.timespan.Start, dateFilter.timespan.End)
+	}
 	return fmt.Sprintf("Between %v and %v", dateFilter.timespan.Start, dateFilter.timespan.End)
 }
 
@@ -61,21 +112,25 @@ func NewStatusFilter(comparison filter.FilterComparison, statuses ...string) *St
 	return &StatusFilter{comparison: comparison, statuses: statuses}
 }
 
-func StatusFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
-	switch comparison {
-	case filter.FilterGt, filter.FilterLt:
-		return nil, errors.New("status filter does not support comparison > or <")
-	}
+// StatusFilterFn matches the built in statuses, apart from none, and those declared in the config
+func StatusFilterFn(statuses Statuses) parser.ToFilterFn {
+	return func(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+		switch comparison {
+		case filter.FilterGt, filter.FilterLt:
+			return nil, errors.New("status filter does not support comparison > or <")
+		case filter.FilterLike:
+			return nil, errors.New("status filter does not support comparison ~")
+		}
 
-	validStatuses := map[string]struct{}{WaitingStatus: {}, SkippedStatus: {}, DoneStatus: {}, BumpedStatus: {}}
-	// allow usage of OR split - beta feature
-	statusValues := strings.Split(val, "|")
-	for _, val := range statusValues {
-		if _, ok := validStatuses[val]; !ok {
-			return nil, errors.New("invalid status")
+		// "|" is shorthand for or between statuses, from before the filter language had or
+		statusValues := strings.Split(val, "|")
+		for _, val := range statusValues {
+			if !statuses.Valid(val) || val == NoStatus {
+				return nil, errors.New("invalid status")
+			}
 		}
+		return NewStatusFilter(comparison, statusValues...), nil
 	}
-	return NewStatusFilter(comparison, statusValues...), nil
 }
 
 func (statusFilter *StatusFilter) Match(matchable filter.Matchable) (bool, error) {
@@ -123,20 +178,16 @@ func TagFilterFn(store Store) parser.ToFilterFn {
 		switch comparison {
 		case filter.FilterGt, filter.FilterLt:
 			return nil, errors.New("tag filter does not support > or <")
+		case filter.FilterLike:
+			return nil, errors.New("tag filter does not support ~")
 		}
 		return NewTagFilter(store, comparison, val), nil
 	}
 }
 
 func (tagFilter *TagFilter) Match(matchable filter.Matchable) (bool, error) {
-	tokenizer := &parser.Tokenizer{}
-	tokenResult, err := tokenizer.Tokenize(matchable.Data())
-	if err != nil {
-		return false, err
-	}
-
 	if tagFilter.comparison == filter.FilterEq {
-		for _, res := range tokenResult.Tags {
+		for _, res := range matchable.Tags() {
 			// more matching eq, if we ever do find a match
 			// evaluate to true
 			if tagFilter.tagName == res {
@@ -147,7 +198,7 @@ func (tagFilter *TagFilter) Match(matchable filter.Matchable) (bool, error) {
 	}
 
 	if tagFilter.comparison == filter.FilterNe {
-		for _, res := range tokenResult.Tags {
+		for _, res := range matchable.Tags() {
 			// for matching the negative, if we ever _do_ find a match,
 			// it should evaluate to false
 			if tagFilter.tagName == res {
@@ -161,6 +212,30 @@ func (tagFilter *TagFilter) Match(matchable filter.Matchable) (bool, error) {
 	return false, errors.New("unrecognized comparison")
 }
 
+// ConnectionFilter matches items with a [[<type>:<id>]] connection of its type to any item. It
+// isn't written in filter strings, but lets stores find connected items through their indexes.
+type ConnectionFilter struct {
+	connectionType string
+}
+
+func NewConnectionFilter(connectionType string) *ConnectionFilter {
+	return &ConnectionFilter{connectionType: connectionType}
+}
+
+func (connectionFilter *ConnectionFilter) Match(matchable filter.Matchable) (bool, error) {
+	for _, connection := range matchable.Connections() {
+		connectionType, _ := parser.SplitConnection(connection)
+		if connectionType == connectionFilter.connectionType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (connectionFilter *ConnectionFilter) String() string {
+	return fmt.Sprintf("Connection %s", connectionFilter.connectionType)
+}
+
 func (tagFilter *TagFilter) String() string {
 	return fmt.Sprintf("Tag %v %s", tagFilter.comparison, tagFilter.tagName)
 }
@@ -169,17 +244,21 @@ type GroupFilter struct {
 	comparison   filter.FilterComparison
 	name         string
 	groupFilters []filter.Filter
+	options      listOptions // any sort, limit and offset saved in the group, used when listing it
 }
 
 func NewGroupFilter(comparison filter.FilterComparison, name string, filters []filter.Filter) *GroupFilter {
-	return &GroupFilter{comparison: comparison, name: name, groupFilters: filters}
+	options, groupFilters := findListOptions(filters)
+	return &GroupFilter{comparison: comparison, name: name, groupFilters: groupFilters, options: options}
 }
 
-func GroupFilterFn(store Store) parser.ToFilterFn {
+func GroupFilterFn(store Store, kinds Kinds, statuses Statuses) parser.ToFilterFn {
 	return func(comparison filter.FilterComparison, val string) (filter.Filter, error) {
 		switch comparison {
 		case filter.FilterGt, filter.FilterLt:
 			return nil, errors.New("group filter does not support > or <")
+		case filter.FilterLike:
+			return nil, errors.New("group filter does not support ~")
 		}
 
 		group, err := store.FindGroupByName(val)
@@ -187,12 +266,12 @@ func GroupFilterFn(store Store) parser.ToFilterFn {
 			return nil, fmt.Errorf("Failed to find group by name: %w", err)
 		}
 
-		filters, err := group.Filters(store)
+		filters, err := group.Filters(store, kinds, statuses)
 		if err != nil {
 			return nil, err
 		}
 
-		return &GroupFilter{comparison: comparison, name: group.Name, groupFilters: filters}, nil
+		return NewGroupFilter(comparison, group.Name, filters), nil
 	}
 }
 
@@ -228,9 +307,12 @@ func (groupFilter *GroupFilter) String() string {
 	return fmt.Sprintf("Group %v %s", groupFilter.comparison, groupFilter.name)
 }
 
+// KindFilter matches items by their kind. Kinds declared in the config are matched by
+// name, so kind=task doesn't match them even if they have a status like a task.
 type KindFilter struct {
 	comparison filter.FilterComparison
 	matchKind  Kind
+	customKind string // empty for task and note
 }
 
 func NewKindFilter(comparison filter.FilterComparison, matchKind Kind) *KindFilter {
@@ -240,16 +322,28 @@ func NewKindFilter(comparison filter.FilterComparison, matchKind Kind) *KindFilt
 	}
 }
 
-func KindFilterFn(comparison filter.FilterComparison, matchKind string) (filter.Filter, error) {
-	switch comparison {
-	case filter.FilterGt, filter.FilterLt:
-		return nil, errors.New("kind filter does not support > or <")
+func NewKindFilterFor(comparison filter.FilterComparison, definition *KindDefinition) *KindFilter {
+	return &KindFilter{
+		comparison: comparison,
+		matchKind:  definition.Kind,
+		customKind: definition.custom(),
 	}
-	kind := StringToKind(matchKind)
-	if kind <= 0 {
-		return nil, fmt.Errorf("kind %q not found", matchKind)
+}
+
+func KindFilterFn(kinds Kinds) parser.ToFilterFn {
+	return func(comparison filter.FilterComparison, matchKind string) (filter.Filter, error) {
+		switch comparison {
+		case filter.FilterGt, filter.FilterLt:
+			return nil, errors.New("kind filter does not support > or <")
+		case filter.FilterLike:
+			return nil, errors.New("kind filter does not support ~")
+		}
+		definition, err := kinds.Find(matchKind)
+		if err != nil {
+			return nil, err
+		}
+		return NewKindFilterFor(comparison, definition), nil
 	}
-	return NewKindFilter(comparison, StringToKind(matchKind)), nil
 }
 
 func (kindFilter *KindFilter) Match(matchable filter.Matchable) (bool, error) {
@@ -257,12 +351,320 @@ func (kindFilter *KindFilter) Match(matchable filter.Matchable) (bool, error) {
 	if kind <= 0 {
 		kind = Task
 	}
+	matches := kind == kindFilter.matchKind && matchable.CustomKind() == kindFilter.customKind
 
 	switch kindFilter.comparison {
 	case filter.FilterEq:
-		return (kind == kindFilter.matchKind), nil
+		return matches, nil
 	case filter.FilterNe:
-		return (kind != kindFilter.matchKind), nil
+		return !matches, nil
 	}
 	return false, fmt.Errorf("failed to compare kind correctly")
 }
+
+type TextFilter struct {
+	comparison filter.FilterComparison
+	text       string
+	words      []string
+}
+
+func NewTextFilter(comparison filter.FilterComparison, text string) *TextFilter {
+	tokenizer := &parser.Tokenizer{}
+	return &TextFilter{comparison: comparison, text: text, words: tokenizer.Words(text)}
+}
+
+// TextFilterFn matches items containing all the given words with = (or none of them with !=),
+// and items containing the words as a phrase, in order, with ~
+func TextFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	switch comparison {
+	case filter.FilterGt, filter.FilterLt:
+		return nil, errors.New("text filter does not support > or <")
+	}
+	textFilter := NewTextFilter(comparison, val)
+	if len(textFilter.words) == 0 {
+		return nil, fmt.Errorf("text filter %q has no words to search for", val)
+	}
+	return textFilter, nil
+}
+
+func (textFilter *TextFilter) Match(matchable filter.Matchable) (bool, error) {
+	tokenizer := &parser.Tokenizer{}
+	itemWords := tokenizer.Words(matchable.Data())
+
+	switch textFilter.comparison {
+	case filter.FilterEq:
+		return containsAllWords(itemWords, textFilter.words), nil
+	case filter.FilterNe:
+		return !containsAllWords(itemWords, textFilter.words), nil
+	case filter.FilterLike:
+		return containsPhrase(itemWords, textFilter.words), nil
+	}
+	return false, errors.New("unrecognized comparison")
+}
+
+func (textFilter *TextFilter) String() string {
+	return fmt.Sprintf("Text %v %q", textFilter.comparison, textFilter.text)
+}
+
+// indexedWords are the words an item must contain to match, used by stores to narrow their search
+func (textFilter *TextFilter) indexedWords() []string {
+	switch textFilter.comparison {
+	case filter.FilterEq, filter.FilterLike:
+		return textFilter.words
+	}
+	return nil
+}
+
+func containsAllWords(itemWords []string, words []string) bool {
+	present := map[string]bool{}
+	for _, word := range itemWords {
+		present[word] = true
+	}
+	for _, word := range words {
+		if !present[word] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPhrase(itemWords []string, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(itemWords); i++ {
+		match := true
+		for j, word := range phrase {
+			if itemWords[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// DueFilter matches items due within its timespan. Items without a due date never match, apart
+// from with !=, which matches items not due within it.
+type DueFilter struct {
+	comparison filter.FilterComparison
+	timespan   *Timespan
+}
+
+func NewDueFilter(comparison filter.FilterComparison, timespan *Timespan) *DueFilter {
+	return &DueFilter{comparison: comparison, timespan: timespan}
+}
+
+// DueFilterFn takes the same times and ranges as the date filter
+func DueFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	due, err := timeFilterSpan("due", comparison, val)
+	if err != nil {
+		return nil, err
+	}
+	return NewDueFilter(comparison, due), nil
+}
+
+func (dueFilter *DueFilter) Match(matchable filter.Matchable) (bool, error) {
+	due := matchable.Due()
+	within := due != 0 && due >= dueFilter.timespan.Start.Unix() && due <= dueFilter.timespan.End.Unix()
+	if dueFilter.comparison == filter.FilterNe {
+		return !within, nil
+	}
+	return within, nil
+}
+
+func (dueFilter *DueFilter) String() string {
+	if dueFilter.comparison == filter.FilterNe {
+		return fmt.Sprintf("Not due between %v and %v", dueFilter.timespan.Start, dueFilter.timespan.End)
+	}
+	return fmt.Sprintf("Due between %v and %v", dueFilter.timespan.Start, dueFilter.timespan.End)
+}
+
+// PriorityFilter compares the priority of items, where lower numbers are more urgent, so
+// priority<3 matches high and medium priority items. Items without a priority only match
+// = none and != comparisons.
+type PriorityFilter struct {
+	comparison filter.FilterComparison
+	priority   Priority
+}
+
+func NewPriorityFilter(comparison filter.FilterComparison, priority Priority) *PriorityFilter {
+	return &PriorityFilter{comparison: comparison, priority: priority}
+}
+
+func PriorityFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	priority, err := StringToPriority(val)
+	if err != nil {
+		return nil, err
+	}
+	switch comparison {
+	case filter.FilterGt, filter.FilterLt:
+		if priority == NoPriority {
+			return nil, errors.New("priority filter does not support > or < with none")
+		}
+	case filter.FilterLike:
+		return nil, errors.New("priority filter does not support ~")
+	}
+	return NewPriorityFilter(comparison, priority), nil
+}
+
+func (priorityFilter *PriorityFilter) Match(matchable filter.Matchable) (bool, error) {
+	priority := Priority(matchable.Priority())
+	switch priorityFilter.comparison {
+	case filter.FilterEq:
+		return priority == priorityFilter.priority, nil
+	case filter.FilterNe:
+		return priority != priorityFilter.priority, nil
+	case filter.FilterGt:
+		return priority != NoPriority && priority > priorityFilter.priority, nil
+	case filter.FilterLt:
+		return priority != NoPriority && priority < priorityFilter.priority, nil
+	}
+	return false, errors.New("unrecognized comparison")
+}
+
+func (priorityFilter *PriorityFilter) String() string {
+	return fmt.Sprintf("Priority %v %v", priorityFilter.comparison, priorityFilter.priority)
+}
+
+// the timestamps recorded on items, which can be filtered on by name
+const (
+	CreatedTimestamp   = "created"
+	UpdatedTimestamp   = "updated"
+	CompletedTimestamp = "completed"
+)
+
+// TimestampFilter matches items with one of their timestamps within its timespan. Items
+// without that timestamp recorded never match.
+type TimestampFilter struct {
+	field      string
+	comparison filter.FilterComparison
+	timespan   *Timespan
+}
+
+func NewTimestampFilter(field string, comparison filter.FilterComparison, timespan *Timespan) *TimestampFilter {
+	return &TimestampFilter{field: field, comparison: comparison, timespan: timespan}
+}
+
+// TimestampFilterFn filters on the timestamp named field, taking the same times and ranges as the
+// date filter. With != it matches items where the timestamp is outside them, or isn't set.
+func TimestampFilterFn(field string) parser.ToFilterFn {
+	return func(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+		timespan, err := timeFilterSpan(field, comparison, val)
+		if err != nil {
+			return nil, err
+		}
+		return NewTimestampFilter(field, comparison, timespan), nil
+	}
+}
+
+func (timestampFilter *TimestampFilter) Match(matchable filter.Matchable) (bool, error) {
+	var timestamp int64
+	switch timestampFilter.field {
+	case CreatedTimestamp:
+		timestamp = matchable.CreatedAt()
+	case UpdatedTimestamp:
+		timestamp = matchable.UpdatedAt()
+	case CompletedTimestamp:
+		timestamp = matchable.CompletedAt()
+	default:
+		return false, fmt.Errorf("unrecognized timestamp %q", timestampFilter.field)
+	}
+	within := timestamp != 0 && timestamp >= timestampFilter.timespan.Start.Unix() && timestamp <= timestampFilter.timespan.End.Unix()
+	if timestampFilter.comparison == filter.FilterNe {
+		return !within, nil
+	}
+	return within, nil
+}
+
+func (timestampFilter *TimestampFilter) String() string {
+	if timestampFilter.comparison == filter.FilterNe {
+		return fmt.Sprintf("%s not between %v and %v", timestampFilter.field, timestampFilter.timespan.Start, timestampFilter.timespan.End)
+	}
+	return fmt.Sprintf("%s between %v and %v", timestampFilter.field, timestampFilter.timespan.Start, timestampFilter.timespan.End)
+}
+
+// MetaFilter matches items on one of their key:value fields. Values are compared exactly with
+// = and !=, where != also matches items without the field, and as numbers or durations with
+// > and <, depending on what the filter's value is.
+type MetaFilter struct {
+	key        string
+	comparison filter.FilterComparison
+	value      string
+}
+
+func NewMetaFilter(key string, comparison filter.FilterComparison, value string) *MetaFilter {
+	return &MetaFilter{key: strings.ToLower(key), comparison: comparison, value: value}
+}
+
+// MetaFilterFn filters on the field named key, as in "meta.estimate>2h"
+func MetaFilterFn(key string, comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	switch comparison {
+	case filter.FilterGt, filter.FilterLt:
+		_, ok := parseMetaNumber(val)
+		if !ok {
+			return nil, fmt.Errorf("meta.%s filter can only use > or < with a number or duration, not %q", key, val)
+		}
+	case filter.FilterLike:
+		return nil, fmt.Errorf("meta.%s filter does not support ~", key)
+	}
+	return NewMetaFilter(key, comparison, val), nil
+}
+
+func (metaFilter *MetaFilter) Match(matchable filter.Matchable) (bool, error) {
+	value, ok := matchable.Meta()[metaFilter.key]
+	switch metaFilter.comparison {
+	case filter.FilterEq:
+		return ok && value == metaFilter.value, nil
+	case filter.FilterNe:
+		return !ok || value != metaFilter.value, nil
+	case filter.FilterGt, filter.FilterLt:
+		if !ok {
+			return false, nil
+		}
+		want, _ := parseMetaNumber(metaFilter.value)
+		got, ok := parseMetaNumber(value)
+		if !ok || got.duration != want.duration {
+			return false, nil
+		}
+		if metaFilter.comparison == filter.FilterGt {
+			return got.value > want.value, nil
+		}
+		return got.value < want.value, nil
+	}
+	return false, errors.New("unrecognized comparison")
+}
+
+func (metaFilter *MetaFilter) String() string {
+	return fmt.Sprintf("meta.%s %v %v", metaFilter.key, metaFilter.comparison, metaFilter.value)
+}
+
+// metaNumber is a field value that can be compared, either a plain number or a duration in seconds
+type metaNumber struct {
+	value    float64
+	duration bool
+}
+
+// parseMetaNumber parses a field value as a number, or a duration like "90m", "2h", "3d" or "1w"
+func parseMetaNumber(value string) (metaNumber, bool) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err == nil {
+		return metaNumber{value: number}, true
+	}
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		return metaNumber{value: duration.Seconds(), duration: true}, true
+	}
+	if len(value) > 1 {
+		number, err := strconv.ParseFloat(value[:len(value)-1], 64)
+		if err == nil {
+			switch value[len(value)-1] {
+			case 'd':
+				return metaNumber{value: number * 24 * 60 * 60, duration: true}, true
+			case 'w':
+				return metaNumber{value: number * 7 * 24 * 60 * 60, duration: true}, true
+			}
+		}
+	}
+	return metaNumber{}, false
+}
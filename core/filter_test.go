@@ -3,19 +3,20 @@ package core
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/josler/wdid/filter"
 	"gotest.tools/assert"
 )
 
 func TestStatusFilterFunctionSplit(t *testing.T) {
-	statusFilter, _ := StatusFilterFn(filter.FilterNe, "done|waiting")
+	statusFilter, _ := StatusFilterFn(Statuses{})(filter.FilterNe, "done|waiting")
 	assert.Equal(t, statusFilter.(*StatusFilter).comparison, filter.FilterNe, "doesn't set comparison correctly")
 	assert.DeepEqual(t, statusFilter.(*StatusFilter).statuses, []string{"done", "waiting"})
 }
 
 func TestStatusFilterFunctionError(t *testing.T) {
-	_, err := StatusFilterFn(filter.FilterEq, "foobar")
+	_, err := StatusFilterFn(Statuses{})(filter.FilterEq, "foobar")
 	assert.Error(t, err, "invalid status")
 }
 
@@ -47,27 +48,164 @@ func TestDateFilterLtFunction(t *testing.T) {
 }
 
 func TestDateFilterNeFunction(t *testing.T) {
-	_, err := DateFilterFn(filter.FilterNe, "2019-05-18")
-	assert.Error(t, err, "date filter does not support comparison 'ne'")
+	dateFilter, err := DateFilterFn(filter.FilterNe, "2019-05-18..2019-05-20")
+	assert.NilError(t, err)
+	inside := timeAt("2019-05-19 12:00:00 +0000 UTC")
+	outside := timeAt("2019-05-21 12:00:00 +0000 UTC")
+	match, _ := dateFilter.Match(MatchableItem{Item: NewTask("inside", inside)})
+	assert.Assert(t, !match)
+	match, _ = dateFilter.Match(MatchableItem{Item: NewTask("outside", outside)})
+	assert.Assert(t, match)
+}
+
+func TestDateFilterRangeFunction(t *testing.T) {
+	dateFilter, err := DateFilterFn(filter.FilterEq, "2019-05-18..2019-05-20")
+	assert.NilError(t, err)
+	start, _ := TimeParser{Input: "2019-05-18"}.Parse()
+	end, _ := TimeParser{Input: "2019-05-20"}.Parse()
+	assert.DeepEqual(t, dateFilter.(*DateFilter).timespan, NewTimespan(start.Start, end.End))
+
+	dateFilter, err = DateFilterFn(filter.FilterEq, "..2019-05-20")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dateFilter.(*DateFilter).timespan, NewTimespan(Timespan{}.EarliestTime(), end.End))
+
+	_, err = DateFilterFn(filter.FilterGt, "2019-05-18..2019-05-20")
+	assert.Error(t, err, "date filter does not support > or < with a range")
+	_, err = DateFilterFn(filter.FilterEq, "..")
+	assert.Error(t, err, "failed to parse time range with input: .., it needs a start or an end")
+	_, err = DateFilterFn(filter.FilterEq, "2019-05-20..2019-05-18")
+	assert.Error(t, err, "failed to parse time range with input: 2019-05-20..2019-05-18, it ends before it starts")
 }
 
 func TestGroupFilterFunction(t *testing.T) {
 	contextWithStore(func(ctx context.Context, store Store) {
 		group := NewGroup("my group", "tag=#foo")
 		store.SaveGroup(group)
-		groupFilter, err := GroupFilterFn(store)(filter.FilterEq, "my group")
+		groupFilter, err := GroupFilterFn(store, DefaultKinds(), Statuses{})(filter.FilterEq, "my group")
 		assert.NilError(t, err)
 		assert.Equal(t, groupFilter.(*GroupFilter).name, "my group")
 	})
 }
 
 func TestKindFilterFunction(t *testing.T) {
-	kindFilter, _ := KindFilterFn(filter.FilterEq, "note")
+	kindFilter, _ := KindFilterFn(DefaultKinds())(filter.FilterEq, "note")
 	assert.DeepEqual(t, kindFilter.(*KindFilter).comparison, filter.FilterEq)
 	assert.DeepEqual(t, kindFilter.(*KindFilter).matchKind, Note)
 }
 
 func TestKindFilterFunctionError(t *testing.T) {
-	_, err := KindFilterFn(filter.FilterEq, "wrong")
+	_, err := KindFilterFn(DefaultKinds())(filter.FilterEq, "wrong")
 	assert.Error(t, err, "kind \"wrong\" not found")
 }
+
+func TestTextFilterFunction(t *testing.T) {
+	textFilter, err := TextFilterFn(filter.FilterLike, `"Release notes"`)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, textFilter.(*TextFilter).words, []string{"release", "notes"})
+}
+
+func TestTextFilterFunctionError(t *testing.T) {
+	_, err := TextFilterFn(filter.FilterEq, "!!")
+	assert.Error(t, err, "text filter \"!!\" has no words to search for")
+	_, err = TextFilterFn(filter.FilterGt, "notes")
+	assert.Error(t, err, "text filter does not support > or <")
+}
+
+func TestDueFilterFunction(t *testing.T) {
+	dueFilter, err := DueFilterFn(filter.FilterLt, "2019-05-18")
+	assert.NilError(t, err)
+	timespan, _ := TimeParser{Input: "2019-05-18"}.Parse()
+	timespan.Start = Timespan{}.EarliestTime()
+	assert.DeepEqual(t, dueFilter.(*DueFilter).timespan, timespan)
+}
+
+func TestDueFilterRangeFunction(t *testing.T) {
+	dueFilter, err := DueFilterFn(filter.FilterNe, "2019-05-18..2019-05-20")
+	assert.NilError(t, err)
+	start, _ := TimeParser{Input: "2019-05-18"}.Parse()
+	end, _ := TimeParser{Input: "2019-05-20"}.Parse()
+	assert.DeepEqual(t, dueFilter.(*DueFilter).timespan, NewTimespan(start.Start, end.End))
+
+	matched, _ := dueFilter.Match(MatchableItem{Item: &Item{due: timeAt("2019-05-19 12:00:00 +0000 UTC")}})
+	assert.Assert(t, !matched)
+	matched, _ = dueFilter.Match(MatchableItem{Item: &Item{due: timeAt("2019-05-22 12:00:00 +0000 UTC")}})
+	assert.Assert(t, matched)
+	matched, _ = dueFilter.Match(MatchableItem{Item: &Item{}})
+	assert.Assert(t, matched)
+}
+
+func TestDueFilterFunctionError(t *testing.T) {
+	_, err := DueFilterFn(filter.FilterGt, "2019-05-18..2019-05-20")
+	assert.Error(t, err, "due filter does not support > or < with a range")
+	_, err = DueFilterFn(filter.FilterLike, "2019-05-18")
+	assert.Error(t, err, "due filter does not support comparison ~")
+}
+
+func TestTimestampFilterFunction(t *testing.T) {
+	completedFilter, err := TimestampFilterFn(CompletedTimestamp)(filter.FilterGt, "2019-05-18")
+	assert.NilError(t, err)
+	timespan, _ := TimeParser{Input: "2019-05-18"}.Parse()
+	timespan.End = Timespan{}.LatestTime()
+	assert.DeepEqual(t, completedFilter.(*TimestampFilter).timespan, timespan)
+
+	matched, _ := completedFilter.Match(MatchableItem{Item: &Item{completedAt: time.Now()}})
+	assert.Assert(t, matched)
+	matched, _ = completedFilter.Match(MatchableItem{Item: &Item{createdAt: time.Now()}})
+	assert.Assert(t, !matched)
+
+	updatedFilter, err := TimestampFilterFn(UpdatedTimestamp)(filter.FilterNe, "2019-05-18..2019-05-20")
+	assert.NilError(t, err)
+	matched, _ = updatedFilter.Match(MatchableItem{Item: &Item{updatedAt: timeAt("2019-05-19 12:00:00 +0000 UTC")}})
+	assert.Assert(t, !matched)
+	matched, _ = updatedFilter.Match(MatchableItem{Item: &Item{updatedAt: time.Now()}})
+	assert.Assert(t, matched)
+
+	_, err = TimestampFilterFn(CreatedTimestamp)(filter.FilterLt, "2019-05-18..2019-05-20")
+	assert.Error(t, err, "created filter does not support > or < with a range")
+}
+
+func TestMetaFilterFunction(t *testing.T) {
+	filters, err := DefaultParser(NewMemoryStore(), DefaultKinds(), Statuses{}).Parse("meta.Estimate>1.5d")
+	assert.NilError(t, err)
+	assert.Equal(t, filters[0].(*MetaFilter).key, "estimate")
+	assert.Equal(t, filters[0].(*MetaFilter).value, "1.5d")
+
+	matched, _ := filters[0].Match(MatchableItem{Item: &Item{data: "migrate estimate:1w"}})
+	assert.Assert(t, matched)
+	matched, _ = filters[0].Match(MatchableItem{Item: &Item{data: "migrate estimate:36h"}})
+	assert.Assert(t, !matched)
+	matched, _ = filters[0].Match(MatchableItem{Item: &Item{data: "migrate estimate:40"}})
+	assert.Assert(t, !matched)
+
+	_, err = MetaFilterFn("client", filter.FilterGt, "acme")
+	assert.Error(t, err, "meta.client filter can only use > or < with a number or duration, not \"acme\"")
+	_, err = MetaFilterFn("client", filter.FilterLike, "acme")
+	assert.Error(t, err, "meta.client filter does not support ~")
+}
+
+func TestPriorityFilterFunction(t *testing.T) {
+	priorityFilter, err := PriorityFilterFn(filter.FilterLt, "low")
+	assert.NilError(t, err)
+	assert.Equal(t, priorityFilter.(*PriorityFilter).priority, LowPriority)
+
+	matched, _ := priorityFilter.Match(MatchableItem{Item: &Item{priority: HighPriority}})
+	assert.Assert(t, matched)
+	matched, _ = priorityFilter.Match(MatchableItem{Item: &Item{}})
+	assert.Assert(t, !matched)
+}
+
+func TestPriorityFilterFunctionError(t *testing.T) {
+	_, err := PriorityFilterFn(filter.FilterEq, "5")
+	assert.Error(t, err, "priority \"5\" not found")
+	_, err = PriorityFilterFn(filter.FilterGt, "none")
+	assert.Error(t, err, "priority filter does not support > or < with none")
+	_, err = PriorityFilterFn(filter.FilterLike, "1")
+	assert.Error(t, err, "priority filter does not support ~")
+}
+
+func TestTagFilterFunctionLikeError(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		_, err := TagFilterFn(store)(filter.FilterLike, "#foo")
+		assert.Error(t, err, "tag filter does not support ~")
+	})
+}
@@ -14,6 +14,11 @@ func FindAll(ctx context.Context, idString string) ([]*Item, error) {
 func FindOneOrPrint(ctx context.Context, idString string) (*Item, error) {
 	store := ctx.Value("store").(Store)
 	items, err := store.FindAll(idString)
+	return oneOrPrint(ctx, items, err)
+}
+
+// oneOrPrint returns the single item found, printing them all if there's more than one
+func oneOrPrint(ctx context.Context, items []*Item, err error) (*Item, error) {
 	if err != nil {
 		return nil, err
 	}
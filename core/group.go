@@ -23,9 +23,8 @@ func NewGroup(name string, filterString string) *Group {
 	return &Group{Name: name, FilterString: filterString, CreatedAt: time.Now()}
 }
 
-func (g *Group) Filters(store Store) ([]filter.Filter, error) {
-	p := DefaultParser(store)
-	filters, err := p.Parse(g.FilterString)
+func (g *Group) Filters(store Store, kinds Kinds, statuses Statuses) ([]filter.Filter, error) {
+	filters, err := parseFilters(store, kinds, statuses, g.FilterString)
 	if err != nil {
 		return []filter.Filter{}, err
 	}
@@ -37,7 +36,7 @@ func CreateGroup(ctx context.Context, name string, filterString string) error {
 	group := NewGroup(name, filterString)
 
 	// validate filters
-	_, err := group.Filters(store)
+	_, err := group.Filters(store, kindsFromContext(ctx), statusesFromContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -27,28 +27,84 @@ func Import(ctx context.Context, filename string) error {
 	return ReadToStore(ctx, f)
 }
 
+// ReadToStore imports everything from f, or nothing if any item fails to save
 func ReadToStore(ctx context.Context, f io.Reader) error {
+	return withTransaction(ctx, func(ctx context.Context) error {
+		return readToStore(ctx, f)
+	})
+}
+
+func readToStore(ctx context.Context, f io.Reader) error {
 	store := ctx.Value("store").(Store)
 	items := []*Item{}
 	itemCreator := &ItemCreator{ctx: ctx}
+	kinds := kindsFromContext(ctx)
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		data := scanner.Text()
+		if strings.TrimSpace(data) == "" {
+			continue
+		}
 		split := strings.Split(data, "\t")
+		if len(split) < 6 {
+			return fmt.Errorf("failed to import %s: expected at least 6 fields, found %d", split[0], len(split))
+		}
 
 		parsedTime, err := time.Parse(time.RFC3339, split[5])
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to import %s: %w", split[0], err)
+		}
+		item := &Item{id: split[0], internalID: split[1], status: split[2], data: split[4], datetime: parsedTime, kind: Task}
+		if len(split) >= 7 {
+			definition, err := kinds.Find(split[6])
+			if err == nil {
+				item.kind = definition.Kind
+				item.customKind = definition.custom()
+			} else {
+				// a custom kind no longer in the config keeps its name, on the kind it must have been based on
+				if item.status == NoStatus {
+					item.kind = Note
+				}
+				item.customKind = split[6]
+			}
+		}
+
+		if len(split) >= 8 && split[7] != "" {
+			due, err := time.Parse(time.RFC3339, split[7])
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", item.id, err)
+			}
+			item.due = due
 		}
-		var kind Kind
-		if len(split) == 7 {
-			kind = StringToKind(split[6])
-		} else {
-			kind = Task
+
+		if len(split) >= 9 && split[8] != "" {
+			priority, err := StringToPriority(split[8])
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", item.id, err)
+			}
+			item.priority = priority
 		}
 
-		item := &Item{id: split[0], internalID: split[1], status: split[2], data: split[4], datetime: parsedTime, kind: kind}
+		if len(split) >= 10 && split[9] != "" {
+			recurrence, err := ParseRecurrence(split[9])
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", item.id, err)
+			}
+			item.SetRecurrence(recurrence)
+		}
+
+		// created, updated and completed, in that order
+		timestamps := []*time.Time{&item.createdAt, &item.updatedAt, &item.completedAt}
+		for n, timestamp := range timestamps {
+			if len(split) >= 11+n && split[10+n] != "" {
+				parsed, err := time.Parse(time.RFC3339, split[10+n])
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", item.id, err)
+				}
+				*timestamp = parsed
+			}
+		}
 
 		refID := split[3]
 		if strings.HasPrefix(refID, "->") {
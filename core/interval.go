@@ -0,0 +1,52 @@
+package core
+
+import (
+	"time"
+)
+
+// Interval is a span of time worked on an item
+type Interval struct {
+	internalID string
+	ItemID     string
+	Start      time.Time
+	End        time.Time // zero while the interval is still running
+}
+
+func NewInterval(itemID string, start time.Time) *Interval {
+	return &Interval{ItemID: itemID, Start: start}
+}
+
+func (interval *Interval) Running() bool {
+	return interval.End.IsZero()
+}
+
+// Duration is how long the interval lasted, or has lasted until now if it's still running
+func (interval *Interval) Duration(now time.Time) time.Duration {
+	if interval.Running() {
+		return now.Sub(interval.Start)
+	}
+	return interval.End.Sub(interval.Start)
+}
+
+// TrackedTime sums the duration of intervals, up until now for any still running
+func TrackedTime(intervals []*Interval, now time.Time) time.Duration {
+	var total time.Duration
+	for _, interval := range intervals {
+		total += interval.Duration(now)
+	}
+	return total
+}
+
+// findExactInterval finds the interval for itemID that started at start
+func findExactInterval(store Store, itemID string, start time.Time) (*Interval, error) {
+	intervals, err := store.ListIntervals(itemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, interval := range intervals {
+		if interval.Start.Unix() == start.Unix() {
+			return interval, nil
+		}
+	}
+	return nil, ErrNotFound
+}
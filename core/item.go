@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/josler/wdid/parser"
@@ -44,6 +45,45 @@ func StringToKind(kindstring string) Kind {
 	return 0
 }
 
+// Priority orders tasks by urgency, from HighPriority (1) down to LowestPriority (4)
+type Priority int
+
+const (
+	NoPriority Priority = iota
+	HighPriority
+	MediumPriority
+	LowPriority
+	LowestPriority
+)
+
+func (p Priority) String() string {
+	if p == NoPriority {
+		return ""
+	}
+	return strconv.Itoa(int(p))
+}
+
+// StringToPriority accepts a priority from 1 to 4, one of high, medium, low or lowest, or none
+func StringToPriority(prioritystring string) (Priority, error) {
+	switch prioritystring {
+	case "none":
+		return NoPriority, nil
+	case "high":
+		return HighPriority, nil
+	case "medium":
+		return MediumPriority, nil
+	case "low":
+		return LowPriority, nil
+	case "lowest":
+		return LowestPriority, nil
+	}
+	number, err := strconv.Atoi(prioritystring)
+	if err != nil || Priority(number) < HighPriority || Priority(number) > LowestPriority {
+		return NoPriority, fmt.Errorf("priority %q not found", prioritystring)
+	}
+	return Priority(number), nil
+}
+
 type Item struct {
 	internalID  string
 	id          string
@@ -52,9 +92,18 @@ type Item struct {
 	data        string
 	tags        []*Tag
 	connections []string // connections to other items
+	meta        map[string]string
 	status      string
 	datetime    time.Time
 	kind        Kind
+	customKind  string    // name of a kind declared in the config, which kind is then the base of
+	due         time.Time // zero if there is no due date
+	priority    Priority
+	recurrence  string    // the rule it recurs with, empty if it doesn't
+	trashedAt   time.Time // only set for items in the trash
+	createdAt   time.Time // zero for items created before it was recorded
+	updatedAt   time.Time // zero for items created before it was recorded
+	completedAt time.Time // when it was last done or skipped, zero if it hasn't been
 }
 
 func (i *Item) ID() string {
@@ -68,6 +117,19 @@ func (i *Item) Kind() Kind {
 	return i.kind
 }
 
+// CustomKind is the name of the kind declared in the config the item is, empty for tasks and notes
+func (i *Item) CustomKind() string {
+	return i.customKind
+}
+
+// KindName is the name of the kind of item, whether task, note or one declared in the config
+func (i *Item) KindName() string {
+	if i.customKind != "" {
+		return i.customKind
+	}
+	return i.Kind().String()
+}
+
 func (i *Item) NextID() string {
 	return i.nextID
 }
@@ -97,6 +159,37 @@ func (i *Item) Connections() []string {
 	return i.connections
 }
 
+// Meta is the key:value fields written in the item's data, keyed by lowercase key
+func (i *Item) Meta() map[string]string {
+	if i.emptyMetadata() {
+		i.generateMetadata()
+	}
+	return i.meta
+}
+
+// BlockedBy is the ids of the items connected to this one as blocking it, with either
+// a [[blocked-by:<id>]] connection on this item or a [[blocks:<id>]] connection on the other.
+// Only the first kind can be found from this item alone.
+func (i *Item) BlockedBy() []string {
+	return i.connectionsOfType(parser.BlockedByConnection)
+}
+
+// Blocks is the ids of the items this one blocks with a [[blocks:<id>]] connection
+func (i *Item) Blocks() []string {
+	return i.connectionsOfType(parser.BlocksConnection)
+}
+
+func (i *Item) connectionsOfType(connectionType string) []string {
+	ids := []string{}
+	for _, connection := range i.Connections() {
+		foundType, id := parser.SplitConnection(connection)
+		if foundType == connectionType {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 func (i *Item) Status() string {
 	return i.status
 }
@@ -105,12 +198,106 @@ func (i *Item) Time() time.Time {
 	return i.datetime
 }
 
+// Due is when a task should be finished by, or the zero time if it has no due date
+func (i *Item) Due() time.Time {
+	return i.due
+}
+
+func (i *Item) SetDue(due time.Time) {
+	i.due = due
+}
+
+// Open is true for tasks that haven't been finished, which are waiting or in a status declared
+// in the config
+func (i *Item) Open() bool {
+	if i.Kind() != Task {
+		return false
+	}
+	switch i.status {
+	case NoStatus, DoneStatus, SkippedStatus, BumpedStatus:
+		return false
+	}
+	return true
+}
+
+// Overdue is true for tasks still open after their due date
+func (i *Item) Overdue() bool {
+	return i.Open() && !i.due.IsZero() && i.due.Before(time.Now())
+}
+
+func (i *Item) Priority() Priority {
+	return i.priority
+}
+
+func (i *Item) SetPriority(priority Priority) {
+	i.priority = priority
+}
+
+// Recurrence is the rule the task recurs with, or empty if it doesn't
+func (i *Item) Recurrence() string {
+	return i.recurrence
+}
+
+// SetRecurrence sets the rule the task recurs with, or stops it recurring if recurrence is nil
+func (i *Item) SetRecurrence(recurrence *Recurrence) {
+	if recurrence == nil {
+		i.recurrence = ""
+		return
+	}
+	i.recurrence = recurrence.String()
+}
+
+// Checklist is the markdown checklist lines in the item's data, its subtasks
+func (i *Item) Checklist() []parser.ChecklistItem {
+	tokenizer := &parser.Tokenizer{}
+	tokenResult, _ := tokenizer.Tokenize(i.data)
+	return tokenResult.Checklist
+}
+
+// ChecklistProgress is how many of the checklist items are ticked, out of the total
+func (i *Item) ChecklistProgress() (int, int) {
+	checklist := i.Checklist()
+	checked := 0
+	for _, checklistItem := range checklist {
+		if checklistItem.Checked {
+			checked++
+		}
+	}
+	return checked, len(checklist)
+}
+
+// TrashedAt is when the item was moved to the trash, or the zero time if it isn't in the trash
+func (i *Item) TrashedAt() time.Time {
+	return i.trashedAt
+}
+
+// CreatedAt is when the item was really created, unlike Time which can be moved
+func (i *Item) CreatedAt() time.Time {
+	return i.createdAt
+}
+
+// UpdatedAt is when the item was last changed
+func (i *Item) UpdatedAt() time.Time {
+	return i.updatedAt
+}
+
+// CompletedAt is when the task was last done or skipped, or the zero time if it hasn't been
+func (i *Item) CompletedAt() time.Time {
+	return i.completedAt
+}
+
+func (i *Item) touch() {
+	i.updatedAt = time.Now()
+}
+
 func (i *Item) Do() {
 	if i.Kind() != Task {
 		return // do does nothing with non tasks
 	}
 	if i.status != BumpedStatus {
 		i.status = DoneStatus
+		i.touch()
+		i.completedAt = i.updatedAt
 	}
 }
 
@@ -120,6 +307,8 @@ func (i *Item) Skip() {
 	}
 	if i.status != BumpedStatus {
 		i.status = SkippedStatus
+		i.touch()
+		i.completedAt = i.updatedAt
 	}
 }
 
@@ -128,7 +317,40 @@ func (i *Item) Bump(newTime time.Time) *Item {
 		return i // do does nothing with non tasks
 	}
 	i.status = BumpedStatus
+	i.touch()
 	newItem := NewTask(i.data, newTime)
+	newItem.customKind = i.customKind
+	newItem.due = i.due
+	newItem.priority = i.priority
+	newItem.recurrence = i.recurrence
+	i.nextID = newItem.ID()
+	newItem.previousID = i.ID()
+	return newItem
+}
+
+// Recur creates the next occurrence of a done or skipped recurring task, linked to this one
+// the same way as a bump. It returns nil if the task shouldn't recur.
+func (i *Item) Recur(now time.Time) *Item {
+	if i.Kind() != Task || i.recurrence == "" || i.nextID != "" {
+		return nil
+	}
+	if i.status != DoneStatus && i.status != SkippedStatus {
+		return nil
+	}
+	recurrence, err := ParseRecurrence(i.recurrence)
+	if err != nil {
+		return nil
+	}
+
+	at := recurrence.NextAfter(i.datetime, now)
+	newItem := NewTask(i.data, at)
+	newItem.customKind = i.customKind
+	if !i.due.IsZero() {
+		// due just as long after the next occurrence as it was after this one
+		newItem.due = i.due.Add(at.Sub(i.datetime))
+	}
+	newItem.priority = i.priority
+	newItem.recurrence = i.recurrence
 	i.nextID = newItem.ID()
 	newItem.previousID = i.ID()
 	return newItem
@@ -154,12 +376,26 @@ func (i *Item) SetKind(kind Kind) {
 	}
 }
 
+// SetKindDefinition makes the item the kind defined, with the status that kind starts with if its
+// base kind changes
+func (i *Item) SetKindDefinition(definition *KindDefinition) {
+	if definition.Kind != i.Kind() {
+		i.SetKind(definition.Kind)
+		if i.kind == Task {
+			i.status = WaitingStatus
+		}
+	}
+	i.customKind = definition.custom()
+}
+
 func (i *Item) generateMetadata() {
 	i.tags = []*Tag{} // always init
 	i.connections = []string{}
+	i.meta = map[string]string{}
 	tokenizer := &parser.Tokenizer{}
 	tokenResult, err := tokenizer.Tokenize(i.data)
 	if err == nil {
+		i.meta = tokenResult.Metadata
 		for _, resultTag := range tokenResult.Tags {
 			i.tags = append(i.tags, NewTag(resultTag))
 		}
@@ -171,13 +407,15 @@ func (i *Item) generateMetadata() {
 }
 
 func (i *Item) emptyMetadata() bool {
-	return i.tags == nil && i.connections == nil
+	return i.tags == nil && i.connections == nil || i.meta == nil
 }
 
 func NewTask(data string, at time.Time) *Item {
-	return &Item{id: GenerateID(at), data: data, status: WaitingStatus, datetime: at, kind: Task}
+	now := time.Now()
+	return &Item{id: GenerateID(at), data: data, status: WaitingStatus, datetime: at, kind: Task, createdAt: now, updatedAt: now}
 }
 
 func NewNote(data string, at time.Time) *Item {
-	return &Item{id: GenerateID(at), data: data, status: NoStatus, datetime: at, kind: Note}
+	now := time.Now()
+	return &Item{id: GenerateID(at), data: data, status: NoStatus, datetime: at, kind: Note, createdAt: now, updatedAt: now}
 }
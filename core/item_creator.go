@@ -14,8 +14,46 @@ type ItemCreator struct {
 }
 
 func (ic *ItemCreator) CreateTask(data string, at time.Time) (*Item, error) {
+	return ic.CreateTaskWith(data, at, "", "", "")
+}
+
+// CreateTaskWith creates a task due at dueString, with priorityString and recurring with recurString,
+// any of which are overridden by an inline due date, priority or recurrence in data
+func (ic *ItemCreator) CreateTaskWith(data string, at time.Time, dueString string, priorityString string, recurString string) (*Item, error) {
+	return ic.CreateKindWith(DefaultKinds()[Task.String()], data, at, dueString, priorityString, recurString)
+}
+
+// CreateKindWith creates an item of the kind defined, like CreateTaskWith. Only kinds with a
+// status can be due, have a priority or recur.
+func (ic *ItemCreator) CreateKindWith(definition *KindDefinition, data string, at time.Time, dueString string, priorityString string, recurString string) (*Item, error) {
 	store := ic.ctx.Value("store").(Store)
+	if definition.Kind != Task {
+		if dueString != "" || priorityString != "" || recurString != "" {
+			return nil, fmt.Errorf("%s has no status, so can't be due, have a priority or recur", definition.Name)
+		}
+		item := NewNote(data, at)
+		item.SetKindDefinition(definition)
+		return ic.persistItem(item, store)
+	}
+
 	item := NewTask(data, at)
+	item.SetKindDefinition(definition)
+	err := ic.maybeNewDue(item, dueString)
+	if err != nil {
+		return nil, err
+	}
+	err = ic.maybeNewPriority(item, priorityString)
+	if err != nil {
+		return nil, err
+	}
+	err = ic.maybeNewRecurrence(item, recurString)
+	if err != nil {
+		return nil, err
+	}
+	err = ic.maybeInline(item)
+	if err != nil {
+		return nil, err
+	}
 	return ic.persistItem(item, store)
 }
 
@@ -48,6 +86,108 @@ func (ic *ItemCreator) maybeNewTime(item *Item, timeString string) error {
 	return nil
 }
 
+func (ic *ItemCreator) maybeNewDue(item *Item, dueString string) error {
+	if dueString != "" {
+		span, err := TimeParser{Input: dueString}.Parse()
+		if err != nil {
+			return err
+		}
+		// due by the end of the time given, so "friday" means any time on friday
+		item.due = span.End
+	}
+	return nil
+}
+
+func (ic *ItemCreator) maybeNewPriority(item *Item, priorityString string) error {
+	if priorityString != "" {
+		priority, err := StringToPriority(priorityString)
+		if err != nil {
+			return err
+		}
+		item.priority = priority
+	}
+	return nil
+}
+
+func (ic *ItemCreator) maybeNewRecurrence(item *Item, recurString string) error {
+	if recurString != "" {
+		recurrence, err := ParseRecurrence(recurString)
+		if err != nil {
+			return err
+		}
+		recurrence.pinTo(item.Time())
+		item.SetRecurrence(recurrence)
+	}
+	return nil
+}
+
+// maybeInline applies any due date, priority or recurrence written inline in the data of a task
+func (ic *ItemCreator) maybeInline(item *Item) error {
+	err := ic.maybeInlineDue(item)
+	if err != nil {
+		return err
+	}
+	err = ic.maybeInlinePriority(item)
+	if err != nil {
+		return err
+	}
+	return ic.maybeInlineRecurrence(item)
+}
+
+// maybeInlineRecurrence takes a recurrence written as "recur:<rule>" out of the data of a task
+func (ic *ItemCreator) maybeInlineRecurrence(item *Item) error {
+	if item.Kind() != Task {
+		return nil
+	}
+	tokenizer := &parser.Tokenizer{}
+	recurString, data := tokenizer.Recur(item.data)
+	if recurString == "" {
+		return nil
+	}
+	err := ic.maybeNewRecurrence(item, recurString)
+	if err != nil {
+		return err
+	}
+	item.data = data
+	return nil
+}
+
+// maybeInlinePriority takes a priority written as "!1" to "!4" out of the data of a task
+func (ic *ItemCreator) maybeInlinePriority(item *Item) error {
+	if item.Kind() != Task {
+		return nil
+	}
+	tokenizer := &parser.Tokenizer{}
+	priorityString, data := tokenizer.Priority(item.data)
+	if priorityString == "" {
+		return nil
+	}
+	err := ic.maybeNewPriority(item, priorityString)
+	if err != nil {
+		return err
+	}
+	item.data = data
+	return nil
+}
+
+// maybeInlineDue takes a due date written as "due:<time>" out of the data of a task
+func (ic *ItemCreator) maybeInlineDue(item *Item) error {
+	if item.Kind() != Task {
+		return nil
+	}
+	tokenizer := &parser.Tokenizer{}
+	dueString, data := tokenizer.Due(item.data)
+	if dueString == "" {
+		return nil
+	}
+	err := ic.maybeNewDue(item, dueString)
+	if err != nil {
+		return err
+	}
+	item.data = data
+	return nil
+}
+
 func (ic *ItemCreator) maybeNewDescription(item *Item, data string) error {
 	if data != "" {
 		item.data = data
@@ -65,6 +205,11 @@ func (ic *ItemCreator) Edit(item *Item, data string, timeString string) (*Item,
 	if err != nil {
 		return nil, err
 	}
+	err = ic.maybeInline(item)
+	if err != nil {
+		return nil, err
+	}
+	item.touch()
 
 	err = ic.GenerateAndSaveMetadata(item)
 	if err != nil {
@@ -97,6 +242,7 @@ func (ic *ItemCreator) GenerateAndSaveMetadata(item *Item) error {
 	if len(tokenResult.Connections) > 0 {
 		item.connections = tokenResult.Connections
 	}
+	item.meta = tokenResult.Metadata
 	return nil
 }
 
@@ -9,12 +9,14 @@ import (
 	"hash/fnv"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/fatih/color"
+	"github.com/josler/wdid/parser"
 	"github.com/juju/ansiterm"
 )
 
@@ -55,9 +57,16 @@ type ItemPrinter struct {
 	successColor color.Attribute
 	noteColor    color.Attribute
 
+	kinds    Kinds    // for the glyph and color of kinds declared in the config
+	statuses Statuses // for the glyph and color of statuses declared in the config
+
 	hasher     hash.Hash32
 	colorWheel map[int]int
 
+	store    Store           // nil if time tracked and blockers can't be looked up
+	running  map[string]bool // ids of items being tracked, loaded when printing a list
+	blockers *blockers       // loaded for the items being printed, nil if they couldn't be
+
 	PrintFormat PrintFormat
 }
 
@@ -69,9 +78,14 @@ func NewItemPrinter(ctx context.Context) *ItemPrinter {
 		waitColor:    color.FgWhite,
 		noteColor:    color.FgBlue,
 		PrintFormat:  GetPrintFormatFromContext(ctx),
+		kinds:        kindsFromContext(ctx),
+		statuses:     statusesFromContext(ctx),
 	}
 
 	base.hasher = fnv.New32a()
+	if store, ok := ctx.Value("store").(Store); ok {
+		base.store = store
+	}
 
 	// there are 216 non "standard" colors
 	// some of them might be hard to read on a regular terminal, so we limit
@@ -118,6 +132,10 @@ func (ip *ItemPrinter) FPrint(w io.Writer, items ...*Item) {
 	tw := ansiterm.NewTabWriter(w, ColMinWidth, 0, 1, ' ', 0)
 	defer tw.Flush()
 
+	if ip.PrintFormat == HumanPrintFormat {
+		ip.loadBlockers(items)
+	}
+
 	if len(items) == 1 {
 		switch ip.PrintFormat {
 		case TextPrintFormat:
@@ -134,6 +152,10 @@ func (ip *ItemPrinter) FPrint(w io.Writer, items ...*Item) {
 	currYear := items[0].Time().Year()
 	showYear := false
 	maxTagStringLength := ip.maxTagStringLength(items)
+	showPriority := ip.anyPriority(items)
+	if ip.PrintFormat == HumanPrintFormat {
+		ip.loadRunning()
+	}
 
 	for _, item := range items {
 		if item.Time().Year() != currYear {
@@ -160,7 +182,7 @@ func (ip *ItemPrinter) FPrint(w io.Writer, items ...*Item) {
 				fmt.Fprintf(tw, "- %s\t\t\n", item.Time().Format("Mon Jan 02"))
 				currDay = item.Time().Day()
 			}
-			ip.fPrintItemHuman(tw, item, maxTagStringLength)
+			ip.fPrintItemHuman(tw, item, maxTagStringLength, showPriority)
 		case JSONPrintFormat:
 			ip.fPrintItemJSON(tw, item)
 		}
@@ -171,12 +193,54 @@ func (ip *ItemPrinter) fPrintItemDetail(w io.Writer, item *Item) {
 	fmt.Fprintf(w, "%s -- %v\n", ip.doneStatus(item), item.Time().Format("Mon, 02 Jan 2006 15:04:05"))
 	baseColor := color.New(color.Bold)
 	baseColor.EnableColor()
-	fmt.Fprintf(w, "Kind: %v\n", item.Kind())
+	fmt.Fprintf(w, "Kind: %v\n", item.KindName())
+	if item.Priority() != NoPriority {
+		fmt.Fprintf(w, "Priority: %s\n", ip.priorityMarker(item))
+	}
+	if !item.Due().IsZero() {
+		due := item.Due().Format("Mon, 02 Jan 2006 15:04:05")
+		if item.Overdue() {
+			overdueColor := color.New(ip.failColor, color.Bold)
+			overdueColor.EnableColor()
+			due = overdueColor.Sprintf("%s (overdue)", due)
+		}
+		fmt.Fprintf(w, "Due: %s\n", due)
+	}
+	if item.Recurrence() != "" {
+		fmt.Fprintf(w, "Recurs: %s\n", item.Recurrence())
+	}
+	if checked, total := item.ChecklistProgress(); total > 0 {
+		fmt.Fprintf(w, "Checklist: %d/%d\n", checked, total)
+	}
+	if blocking := ip.blocking(item); len(blocking) > 0 {
+		blockedColor := color.New(ip.failColor)
+		blockedColor.EnableColor()
+		fmt.Fprintf(w, "Blocked by: %s\n", blockedColor.Sprint(strings.Join(blocking, ", ")))
+	}
+	if tracked := ip.trackedTime(item); tracked != "" {
+		fmt.Fprintf(w, "Tracked: %s\n", tracked)
+	}
+	if !item.CreatedAt().IsZero() {
+		fmt.Fprintf(w, "Created: %v\n", item.CreatedAt().Format("Mon, 02 Jan 2006 15:04:05"))
+	}
+	if !item.UpdatedAt().IsZero() {
+		fmt.Fprintf(w, "Updated: %v\n", item.UpdatedAt().Format("Mon, 02 Jan 2006 15:04:05"))
+	}
+	if !item.CompletedAt().IsZero() {
+		fmt.Fprintf(w, "Completed: %v\n", item.CompletedAt().Format("Mon, 02 Jan 2006 15:04:05"))
+	}
+	if !item.TrashedAt().IsZero() {
+		fmt.Fprintf(w, "Trashed: %v\n", item.TrashedAt().Format("Mon, 02 Jan 2006 15:04:05"))
+	}
+	nextLabel, previousLabel := "Bumped to", "Bumped from"
+	if item.Recurrence() != "" {
+		nextLabel, previousLabel = "Next occurrence", "Previous occurrence"
+	}
 	if item.NextID() != "" {
-		fmt.Fprintf(w, "Bumped to: %s\n", baseColor.Sprintf("%s", item.NextID()))
+		fmt.Fprintf(w, "%s: %s\n", nextLabel, baseColor.Sprintf("%s", item.NextID()))
 	}
 	if item.PreviousID() != "" {
-		fmt.Fprintf(w, "Bumped from: %s\n", baseColor.Sprintf("%s", item.PreviousID()))
+		fmt.Fprintf(w, "%s: %s\n", previousLabel, baseColor.Sprintf("%s", item.PreviousID()))
 	}
 	if len(item.Tags()) != 0 {
 		fmt.Fprintf(w, "Tags: %v\n", baseColor.Sprintf("%s", ip.itemTags(item, true)))
@@ -184,6 +248,14 @@ func (ip *ItemPrinter) fPrintItemDetail(w io.Writer, item *Item) {
 	if len(item.Connections()) != 0 {
 		fmt.Fprintf(w, "Connections: %v\n", baseColor.Sprintf("%s", item.Connections()))
 	}
+	if len(item.Meta()) != 0 {
+		fields := []string{}
+		for key, value := range item.Meta() {
+			fields = append(fields, key+":"+value)
+		}
+		sort.Strings(fields)
+		fmt.Fprintf(w, "Metadata: %v\n", baseColor.Sprintf("%s", strings.Join(fields, " ")))
+	}
 	out, _ := glamour.Render(item.Data(), "dark")
 	fmt.Fprintf(w, "Data:\n%s\n\n", out)
 }
@@ -196,7 +268,8 @@ func (ip *ItemPrinter) fPrintItemCompact(w io.Writer, item *Item) {
 	if item.PreviousID() != "" {
 		refID = "<-" + item.PreviousID()
 	}
-	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%v\n", item.ID(), item.internalID, item.Status(), refID, item.Data(), item.Time().Format(time.RFC3339), item.Kind())
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%v\t%s\t%v\t%s\t%s\t%s\t%s\n", item.ID(), item.internalID, item.Status(), refID, item.Data(), item.Time().Format(time.RFC3339), item.KindName(), formatOptionalTime(item.Due()), item.Priority(), item.Recurrence(),
+		formatOptionalTime(item.CreatedAt()), formatOptionalTime(item.UpdatedAt()), formatOptionalTime(item.CompletedAt()))
 }
 
 type JSONItem struct {
@@ -210,6 +283,16 @@ type JSONItem struct {
 	TimeString string
 	Tags       []string
 	Kind       string
+	DueString  string                 `json:",omitempty"`
+	Priority   int                    `json:",omitempty"`
+	Recurrence string                 `json:",omitempty"`
+	Checklist  []parser.ChecklistItem `json:",omitempty"`
+	Meta       map[string]string      `json:",omitempty"`
+	TrashedAt  string                 `json:",omitempty"`
+
+	CreatedAt   string `json:",omitempty"`
+	UpdatedAt   string `json:",omitempty"`
+	CompletedAt string `json:",omitempty"`
 }
 
 func (ip *ItemPrinter) fPrintItemJSON(w io.Writer, item *Item) {
@@ -228,8 +311,21 @@ func (ip *ItemPrinter) fPrintItemJSON(w io.Writer, item *Item) {
 		Status:     item.Status(),
 		TimeString: item.Time().Format(time.RFC3339),
 		Tags:       tagStrings,
-		Kind:       item.Kind().String(),
+		Kind:       item.KindName(),
+		Priority:   int(item.Priority()),
+		Recurrence: item.Recurrence(),
+		Checklist:  item.Checklist(),
+		Meta:       item.Meta(),
+	}
+	if !item.Due().IsZero() {
+		jsonItem.DueString = item.Due().Format(time.RFC3339)
+	}
+	if !item.TrashedAt().IsZero() {
+		jsonItem.TrashedAt = item.TrashedAt().Format(time.RFC3339)
 	}
+	jsonItem.CreatedAt = formatOptionalTime(item.CreatedAt())
+	jsonItem.UpdatedAt = formatOptionalTime(item.UpdatedAt())
+	jsonItem.CompletedAt = formatOptionalTime(item.CompletedAt())
 	buf := bytes.Buffer{}
 	encoder := json.NewEncoder(&buf)
 	encoder.SetEscapeHTML(false)
@@ -240,9 +336,105 @@ func (ip *ItemPrinter) fPrintItemJSON(w io.Writer, item *Item) {
 	fmt.Fprintf(w, "%s", buf.String())
 }
 
-func (ip *ItemPrinter) fPrintItemHuman(w io.Writer, item *Item, maxTagStringLength int) {
-	dataString := TrimString(strings.Split(item.Data(), "\n")[0], LargestDateLen+ColSpacesLen+ColMinWidth+maxTagStringLength)
-	fmt.Fprintf(w, "%s\t%s\t%s\t\n", ip.doneStatus(item), dataString, ip.itemTags(item, false))
+func (ip *ItemPrinter) fPrintItemHuman(w io.Writer, item *Item, maxTagStringLength int, showPriority bool) {
+	progress := ""
+	if checked, total := item.ChecklistProgress(); total > 0 {
+		progress = fmt.Sprintf(" %d/%d", checked, total)
+	}
+	dataString := TrimString(strings.Split(item.Data(), "\n")[0], LargestDateLen+ColSpacesLen+ColMinWidth+maxTagStringLength+len(progress)) + progress
+	status := ip.doneStatus(item)
+	if len(ip.blocking(item)) > 0 {
+		blockedColor := color.New(ip.failColor)
+		blockedColor.EnableColor()
+		status = fmt.Sprintf("%s %s", status, blockedColor.Sprint("⊘"))
+	}
+	if ip.running[item.ID()] {
+		runningColor := color.New(ip.successColor, color.Bold)
+		runningColor.EnableColor()
+		status = fmt.Sprintf("%s %s", status, runningColor.Sprint("▶"))
+	}
+	if showPriority {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", status, ip.priorityMarker(item), dataString, ip.itemTags(item, false))
+		return
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t\n", status, dataString, ip.itemTags(item, false))
+}
+
+// loadRunning finds which items are being tracked, so they can be marked
+func (ip *ItemPrinter) loadRunning() {
+	ip.running = map[string]bool{}
+	if ip.store == nil {
+		return
+	}
+	running, err := ip.store.ListRunningIntervals()
+	if err != nil {
+		return
+	}
+	for _, interval := range running {
+		ip.running[interval.ItemID] = true
+	}
+}
+
+// loadBlockers finds which tasks block the items being printed, so they can be marked
+func (ip *ItemPrinter) loadBlockers(items []*Item) {
+	ip.blockers = nil
+	if ip.store == nil {
+		return
+	}
+	ip.blockers, _ = loadBlockers(ip.store, items)
+}
+
+// blocking is the ids of the tasks still open that block item, if it's open itself
+func (ip *ItemPrinter) blocking(item *Item) []string {
+	if !item.Open() || ip.blockers == nil {
+		return nil
+	}
+	return ip.blockers.blocking(item.ID(), item.Connections())
+}
+
+// trackedTime is the total time tracked against the item, or empty if there is none
+func (ip *ItemPrinter) trackedTime(item *Item) string {
+	if ip.store == nil {
+		return ""
+	}
+	intervals, err := ip.store.ListIntervals(item.ID())
+	if err != nil || len(intervals) == 0 {
+		return ""
+	}
+	tracked := formatDuration(TrackedTime(intervals, time.Now()))
+	if intervals[len(intervals)-1].Running() {
+		return tracked + " (running)"
+	}
+	return tracked
+}
+
+// anyPriority is true if any of the items have a priority, so the priority column is worth printing
+func (ip *ItemPrinter) anyPriority(items []*Item) bool {
+	for _, item := range items {
+		if item.Priority() != NoPriority {
+			return true
+		}
+	}
+	return false
+}
+
+func (ip *ItemPrinter) priorityMarker(item *Item) string {
+	switch item.Priority() {
+	case NoPriority:
+		return ""
+	case HighPriority:
+		baseColor := color.New(ip.failColor, color.Bold)
+		baseColor.EnableColor()
+		return baseColor.Sprintf("!%v", item.Priority())
+	case MediumPriority:
+		baseColor := color.New(ip.bumpedColor)
+		baseColor.EnableColor()
+		return baseColor.Sprintf("!%v", item.Priority())
+	default:
+		baseColor := color.New(ip.waitColor)
+		baseColor.EnableColor()
+		return baseColor.Sprintf("!%v", item.Priority())
+	}
 }
 
 func (ip *ItemPrinter) itemTags(item *Item, showAll bool) string {
@@ -308,6 +500,19 @@ func (ip *ItemPrinter) tagColor(tagName string, params []int) string {
 }
 
 func (ip *ItemPrinter) doneStatus(item *Item) string {
+	if item.CustomKind() != "" {
+		if status, ok := ip.customKindStatus(item); ok {
+			return status
+		}
+	}
+	if definition, ok := ip.statuses[item.Status()]; ok {
+		baseColor := color.New(definition.Color)
+		if item.Overdue() {
+			baseColor = color.New(ip.failColor, color.Bold)
+		}
+		baseColor.EnableColor()
+		return baseColor.Sprintf("%s %v", definition.Glyph, item.ID())
+	}
 	switch item.Status() {
 	case NoStatus:
 		baseColor := color.New(ip.noteColor)
@@ -322,6 +527,11 @@ func (ip *ItemPrinter) doneStatus(item *Item) string {
 		baseColor.EnableColor()
 		return baseColor.Sprintf("✔ %v", item.ID())
 	case WaitingStatus:
+		if item.Overdue() {
+			baseColor := color.New(ip.failColor, color.Bold)
+			baseColor.EnableColor()
+			return baseColor.Sprintf("! %v", item.ID())
+		}
 		if item.PreviousID() != "" { // i.e. was bumped
 			baseColor := color.New(ip.bumpedColor)
 			baseColor.EnableColor()
@@ -340,3 +550,20 @@ func (ip *ItemPrinter) doneStatus(item *Item) string {
 		return baseColor.Sprintf("? %v", item.ID())
 	}
 }
+
+// customKindStatus shows kinds declared in the config with their own glyph and color while
+// they're statusless or waiting, and like a task otherwise. It's not ok if the kind is no
+// longer declared.
+func (ip *ItemPrinter) customKindStatus(item *Item) (string, bool) {
+	definition := ip.kinds.forItem(item)
+	if definition == nil {
+		return "", false
+	}
+	waiting := item.Status() == WaitingStatus && !item.Overdue() && item.PreviousID() == ""
+	if item.Status() != NoStatus && !waiting {
+		return "", false
+	}
+	baseColor := color.New(definition.Color)
+	baseColor.EnableColor()
+	return baseColor.Sprintf("%s %v", definition.Glyph, item.ID()), true
+}
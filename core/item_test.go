@@ -50,6 +50,33 @@ func TestBump(t *testing.T) {
 	}
 }
 
+func TestBumpKeepsDue(t *testing.T) {
+	bumpedItem := NewTask("foobar", time.Now())
+	bumpedItem.SetDue(time.Now().Add(time.Hour))
+	newItem := bumpedItem.Bump(time.Now())
+	assert.Equal(t, newItem.Due(), bumpedItem.Due())
+}
+
+func TestBumpKeepsPriority(t *testing.T) {
+	bumpedItem := NewTask("foobar", time.Now())
+	bumpedItem.SetPriority(LowPriority)
+	newItem := bumpedItem.Bump(time.Now())
+	assert.Equal(t, newItem.Priority(), LowPriority)
+}
+
+func TestOverdue(t *testing.T) {
+	item := NewTask("foobar", time.Now())
+	assert.Assert(t, !item.Overdue())
+	item.SetDue(time.Now().Add(-time.Hour))
+	assert.Assert(t, item.Overdue())
+	item.Do()
+	assert.Assert(t, !item.Overdue())
+
+	note := NewNote("foobar", time.Now())
+	note.SetDue(time.Now().Add(-time.Hour))
+	assert.Assert(t, !note.Overdue())
+}
+
 func TestDo(t *testing.T) {
 	item := NewTask("foobar", time.Now())
 	item.Do()
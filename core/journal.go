@@ -0,0 +1,337 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// JournalLimit is how many operations are kept to undo
+const JournalLimit = 50
+
+const (
+	itemChange        = "item"         // an item was added, changed or permanently deleted
+	trashChange       = "trash"        // an item was moved to the trash
+	restoreChange     = "restore"      // an item was restored from the trash
+	trashDeleteChange = "trash-delete" // an item was removed from the trash
+	groupChange       = "group"        // a group was added, changed or deleted
+	intervalChange    = "interval"     // time tracked was started, stopped or deleted
+)
+
+// Operation is every change made by a single command, so they can be undone together
+type Operation struct {
+	internalID string
+	Name       string
+	At         time.Time
+	Changes    []*Change
+}
+
+// Change records one thing changing. A nil before means it was created, a nil after that it was deleted.
+type Change struct {
+	Type        string
+	ItemBefore  *itemSnapshot  `json:",omitempty"`
+	ItemAfter   *itemSnapshot  `json:",omitempty"`
+	GroupBefore *groupSnapshot `json:",omitempty"`
+	GroupAfter  *groupSnapshot `json:",omitempty"`
+
+	IntervalBefore *intervalSnapshot `json:",omitempty"`
+	IntervalAfter  *intervalSnapshot `json:",omitempty"`
+}
+
+type itemSnapshot struct {
+	ID         string
+	NextID     string
+	PreviousID string
+	Data       string
+	Status     string
+	Datetime   int64
+	Kind       int64
+	CustomKind string   `json:",omitempty"`
+	Due        int64    `json:",omitempty"`
+	Priority   Priority `json:",omitempty"`
+	Recurrence string   `json:",omitempty"`
+
+	CreatedAt   int64 `json:",omitempty"`
+	UpdatedAt   int64 `json:",omitempty"`
+	CompletedAt int64 `json:",omitempty"`
+}
+
+func snapshotItem(item *Item) *itemSnapshot {
+	return &itemSnapshot{
+		ID:         item.ID(),
+		NextID:     item.NextID(),
+		PreviousID: item.PreviousID(),
+		Data:       item.Data(),
+		Status:     item.Status(),
+		Datetime:   item.Time().Unix(),
+		Kind:       int64(item.kind),
+		CustomKind: item.CustomKind(),
+		Due:        unixOrZero(item.Due()),
+		Priority:   item.Priority(),
+		Recurrence: item.Recurrence(),
+
+		CreatedAt:   unixOrZero(item.CreatedAt()),
+		UpdatedAt:   unixOrZero(item.UpdatedAt()),
+		CompletedAt: unixOrZero(item.CompletedAt()),
+	}
+}
+
+// applyTo sets the item back to how it was in the snapshot
+func (snapshot *itemSnapshot) applyTo(item *Item) {
+	item.id = snapshot.ID
+	item.nextID = snapshot.NextID
+	item.previousID = snapshot.PreviousID
+	item.data = snapshot.Data
+	item.status = snapshot.Status
+	item.datetime = time.Unix(snapshot.Datetime, 0)
+	item.kind = Kind(snapshot.Kind)
+	item.customKind = snapshot.CustomKind
+	item.due = timeOrZero(snapshot.Due)
+	item.priority = snapshot.Priority
+	item.recurrence = snapshot.Recurrence
+	item.createdAt = timeOrZero(snapshot.CreatedAt)
+	item.updatedAt = timeOrZero(snapshot.UpdatedAt)
+	item.completedAt = timeOrZero(snapshot.CompletedAt)
+	item.tags = nil
+	item.connections = nil
+	item.meta = nil
+}
+
+type groupSnapshot struct {
+	Name         string
+	FilterString string
+	CreatedAt    int64
+}
+
+func snapshotGroup(group *Group) *groupSnapshot {
+	return &groupSnapshot{Name: group.Name, FilterString: group.FilterString, CreatedAt: group.CreatedAt.Unix()}
+}
+
+func (snapshot *groupSnapshot) group() *Group {
+	return &Group{Name: snapshot.Name, FilterString: snapshot.FilterString, CreatedAt: time.Unix(snapshot.CreatedAt, 0)}
+}
+
+type intervalSnapshot struct {
+	ItemID string
+	Start  int64
+	End    int64 `json:",omitempty"`
+}
+
+func snapshotInterval(interval *Interval) *intervalSnapshot {
+	return &intervalSnapshot{ItemID: interval.ItemID, Start: interval.Start.Unix(), End: unixOrZero(interval.End)}
+}
+
+func (snapshot *intervalSnapshot) interval() *Interval {
+	return &Interval{ItemID: snapshot.ItemID, Start: time.Unix(snapshot.Start, 0), End: timeOrZero(snapshot.End)}
+}
+
+func encodeChanges(changes []*Change) (string, error) {
+	encoded, err := json.Marshal(changes)
+	return string(encoded), err
+}
+
+func decodeChanges(encoded string) ([]*Change, error) {
+	changes := []*Change{}
+	err := json.Unmarshal([]byte(encoded), &changes)
+	return changes, err
+}
+
+// Journal wraps a store, recording the changes made through it as a single operation,
+// which is saved when the journal is closed
+type Journal struct {
+	Store
+	operation *Operation
+}
+
+func NewJournal(store Store, name string) *Journal {
+	return &Journal{Store: store, operation: &Operation{Name: name}}
+}
+
+func (j *Journal) record(change *Change) {
+	j.operation.Changes = append(j.operation.Changes, change)
+}
+
+func (j *Journal) WithContext(ctx context.Context) Store {
+	return &Journal{Store: j.Store.WithContext(ctx), operation: j.operation}
+}
+
+func (j *Journal) Transaction(f func(tx Store) error) error {
+	recorded := len(j.operation.Changes)
+	err := j.Store.Transaction(func(tx Store) error {
+		return f(&Journal{Store: tx, operation: j.operation})
+	})
+	if err != nil {
+		// nothing was changed, so forget what was recorded
+		j.operation.Changes = j.operation.Changes[:recorded]
+	}
+	return err
+}
+
+func (j *Journal) Save(item *Item) error {
+	var before *itemSnapshot
+	existing, err := findExactItem(j.Store, item.ID())
+	if err == nil {
+		before = snapshotItem(existing)
+	}
+	err = j.Store.Save(item)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: itemChange, ItemBefore: before, ItemAfter: snapshotItem(item)})
+	return nil
+}
+
+func (j *Journal) Delete(item *Item) error {
+	existing, err := findExactItem(j.Store, item.ID())
+	if err != nil {
+		return err
+	}
+	err = j.Store.Delete(item)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: itemChange, ItemBefore: snapshotItem(existing)})
+	return nil
+}
+
+func (j *Journal) Trash(item *Item) error {
+	err := j.Store.Trash(item)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: trashChange, ItemBefore: snapshotItem(item)})
+	return nil
+}
+
+func (j *Journal) Restore(item *Item) error {
+	err := j.Store.Restore(item)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: restoreChange, ItemBefore: snapshotItem(item)})
+	return nil
+}
+
+func (j *Journal) DeleteTrash(item *Item) error {
+	err := j.Store.DeleteTrash(item)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: trashDeleteChange, ItemBefore: snapshotItem(item)})
+	return nil
+}
+
+func (j *Journal) SaveGroup(group *Group) error {
+	var before *groupSnapshot
+	existing, err := j.Store.FindGroupByName(group.Name)
+	if err == nil {
+		before = snapshotGroup(existing)
+	}
+	err = j.Store.SaveGroup(group)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: groupChange, GroupBefore: before, GroupAfter: snapshotGroup(group)})
+	return nil
+}
+
+func (j *Journal) DeleteGroup(group *Group) error {
+	err := j.Store.DeleteGroup(group)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: groupChange, GroupBefore: snapshotGroup(group)})
+	return nil
+}
+
+func (j *Journal) SaveInterval(interval *Interval) error {
+	var before *intervalSnapshot
+	if interval.internalID != "" {
+		existing, err := findExactInterval(j.Store, interval.ItemID, interval.Start)
+		if err == nil {
+			before = snapshotInterval(existing)
+		}
+	}
+	err := j.Store.SaveInterval(interval)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: intervalChange, IntervalBefore: before, IntervalAfter: snapshotInterval(interval)})
+	return nil
+}
+
+func (j *Journal) DeleteInterval(interval *Interval) error {
+	err := j.Store.DeleteInterval(interval)
+	if err != nil {
+		return err
+	}
+	j.record(&Change{Type: intervalChange, IntervalBefore: snapshotInterval(interval)})
+	return nil
+}
+
+// Commit saves the operation, if anything changed, and starts recording a new one
+func (j *Journal) Commit() error {
+	if len(j.operation.Changes) == 0 {
+		return nil
+	}
+	j.operation.At = time.Now()
+	err := j.Store.Transaction(func(tx Store) error {
+		err := tx.SaveOperation(j.operation)
+		if err != nil {
+			return err
+		}
+		operations, err := tx.ListOperations()
+		if err != nil {
+			return err
+		}
+		for i := JournalLimit; i < len(operations); i++ {
+			err = tx.DeleteOperation(operations[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*j.operation = Operation{Name: j.operation.Name}
+	return nil
+}
+
+// Close commits the operation before closing the store
+func (j *Journal) Close() error {
+	err := j.Commit()
+	closeErr := j.Store.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// findExactItem finds the item with exactly the given id, rather than any with it as a prefix
+func findExactItem(store Store, id string) (*Item, error) {
+	items, err := store.FindAll(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ID() == id {
+			return item, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func findExactTrashedItem(store Store, id string) (*Item, error) {
+	items, err := store.FindAllTrash(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ID() == id {
+			return item, nil
+		}
+	}
+	return nil, ErrNotFound
+}
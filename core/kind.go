@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/josler/wdid/config"
+)
+
+// KindDefinition is a kind of item, either task or note, or one declared in the config that
+// behaves like one of them
+type KindDefinition struct {
+	Name  string
+	Kind  Kind   // Task for kinds with a status, Note for statusless kinds
+	Glyph string // empty for task and note, which show their status instead
+	Color color.Attribute
+}
+
+// custom is the name stored on items of a kind declared in the config, empty for task and note
+func (definition *KindDefinition) custom() string {
+	if definition.Name == definition.Kind.String() {
+		return ""
+	}
+	return definition.Name
+}
+
+// Kinds are the kinds of item that can be added, by name
+type Kinds map[string]*KindDefinition
+
+var configColors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// DefaultKinds are just the built in task and note
+func DefaultKinds() Kinds {
+	return Kinds{
+		Task.String(): {Name: Task.String(), Kind: Task},
+		Note.String(): {Name: Note.String(), Kind: Note},
+	}
+}
+
+// NewKinds are the built in kinds, along with those declared in the config
+func NewKinds(configKinds []config.ConfigKind) (Kinds, error) {
+	kinds := DefaultKinds()
+	for _, configKind := range configKinds {
+		if configKind.Name == "" {
+			return nil, errors.New("kind must have a name")
+		}
+		if _, ok := kinds[configKind.Name]; ok {
+			return nil, fmt.Errorf("kind %q is already declared", configKind.Name)
+		}
+		definition := &KindDefinition{Name: configKind.Name, Kind: Note, Glyph: configKind.Glyph, Color: color.FgBlue}
+		if configKind.Status {
+			definition.Kind = Task
+			definition.Color = color.FgWhite
+		}
+		if definition.Glyph == "" {
+			definition.Glyph = "⇒"
+		}
+		if configKind.Color != "" {
+			c, ok := configColors[configKind.Color]
+			if !ok {
+				return nil, fmt.Errorf("kind %q has unknown color %q", configKind.Name, configKind.Color)
+			}
+			definition.Color = c
+		}
+		kinds[configKind.Name] = definition
+	}
+	return kinds, nil
+}
+
+func (kinds Kinds) Find(name string) (*KindDefinition, error) {
+	definition, ok := kinds[name]
+	if !ok {
+		return nil, fmt.Errorf("kind %q not found", name)
+	}
+	return definition, nil
+}
+
+// forItem is the definition of the kind of item, or nil if it's a custom kind no longer in the config
+func (kinds Kinds) forItem(item *Item) *KindDefinition {
+	return kinds[item.KindName()]
+}
+
+// kindsFromContext are the kinds set up from the config, or just task and note if there aren't any
+func kindsFromContext(ctx context.Context) Kinds {
+	kinds, ok := ctx.Value("kinds").(Kinds)
+	if !ok {
+		return DefaultKinds()
+	}
+	return kinds
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/josler/wdid/config"
+	"gotest.tools/assert"
+)
+
+func TestNewKinds(t *testing.T) {
+	kinds, err := NewKinds([]config.ConfigKind{{Name: "meeting", Status: true, Glyph: "◷", Color: "magenta"}, {Name: "idea"}})
+	assert.NilError(t, err)
+	assert.Equal(t, len(kinds), 4)
+	assert.Equal(t, kinds["meeting"].Kind, Task)
+	assert.Equal(t, kinds["idea"].Kind, Note)
+
+	_, err = NewKinds([]config.ConfigKind{{Name: "note"}})
+	assert.Error(t, err, `kind "note" is already declared`)
+	_, err = NewKinds([]config.ConfigKind{{Name: "idea", Color: "mauve"}})
+	assert.Error(t, err, `kind "idea" has unknown color "mauve"`)
+}
+
+func TestAddKind(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		kinds, _ := NewKinds([]config.ConfigKind{{Name: "meeting", Status: true}, {Name: "idea"}})
+		ctx = context.WithValue(ctx, "kinds", kinds)
+
+		err := AddKind(ctx, "meeting", strings.NewReader("standup"), "now", "", "1", "", false)
+		assert.NilError(t, err)
+		meeting := mostRecentItem(store)
+		assert.Equal(t, meeting.KindName(), "meeting")
+		assert.Equal(t, meeting.Priority(), HighPriority)
+
+		// meetings have a status like a task, so can be done
+		err = Do(ctx, meeting.ID())
+		assert.NilError(t, err)
+		items, _ := ListWithoutPrinting(ctx, "kind=meeting,status=done")
+		assert.Equal(t, len(items), 1)
+
+		err = AddKind(ctx, "idea", strings.NewReader("a new app"), "now", "tomorrow", "", "", false)
+		assert.Error(t, err, "idea has no status, so can't be due, have a priority or recur")
+		err = AddKind(ctx, "idea", strings.NewReader("a new app"), "now", "", "", "", true)
+		assert.Error(t, err, "idea has no status, so can't be done")
+		err = AddKind(ctx, "decision", strings.NewReader("use sqlite"), "now", "", "", "", false)
+		assert.Error(t, err, `kind "decision" not found`)
+
+		_, err = ListWithoutPrinting(ctx, "kind=decision")
+		assert.Error(t, err, "kind \"decision\" not found at position 6:\nkind=decision\n     ^")
+	})
+}
@@ -3,12 +3,68 @@ package core
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/josler/wdid/filter"
 )
 
-func List(ctx context.Context, argString string, groupString string) error {
+// ListOptions are a sort, limit and offset to list with, which replace any given in the filters
+type ListOptions struct {
+	Sort   string // fields separated by commas, with a - for descending, e.g. "status,-time"
+	Limit  int    // 0 for no limit
+	Offset int
+}
+
+// apply replaces the sort, limit and offset in filters with the options that are set
+func (options ListOptions) apply(filters []filter.Filter) ([]filter.Filter, error) {
+	optionFilters := []filter.Filter{}
+	if options.Sort != "" {
+		for _, field := range strings.Split(options.Sort, ",") {
+			sortFilter, err := SortFilterFn(filter.FilterEq, strings.TrimSpace(field))
+			if err != nil {
+				return nil, err
+			}
+			optionFilters = append(optionFilters, sortFilter)
+		}
+	}
+	if options.Limit != 0 {
+		limitFilter, err := LimitFilterFn(filter.FilterEq, strconv.Itoa(options.Limit))
+		if err != nil {
+			return nil, err
+		}
+		optionFilters = append(optionFilters, limitFilter)
+	}
+	if options.Offset != 0 {
+		offsetFilter, err := OffsetFilterFn(filter.FilterEq, strconv.Itoa(options.Offset))
+		if err != nil {
+			return nil, err
+		}
+		optionFilters = append(optionFilters, offsetFilter)
+	}
+
+	applied := []filter.Filter{}
+	for _, f := range filters {
+		switch f.(type) {
+		case *SortFilter:
+			if options.Sort != "" {
+				continue
+			}
+		case *LimitFilter:
+			if options.Limit != 0 {
+				continue
+			}
+		case *OffsetFilter:
+			if options.Offset != 0 {
+				continue
+			}
+		}
+		applied = append(applied, f)
+	}
+	return append(applied, optionFilters...), nil
+}
+
+func List(ctx context.Context, argString string, groupString string, options ListOptions) error {
 	v := ctx.Value("verbose")
 	isVerbose := v != nil && v.(bool)
 
@@ -27,9 +83,9 @@ func List(ctx context.Context, argString string, groupString string) error {
 		argString = strings.TrimPrefix(group.FilterString, ",")
 	}
 
-	items, err = listFromTimeString(store, argString)
+	items, err = listFromTimeString(store, argString, options)
 	if err != nil {
-		items, err = listFromFilters(store, argString, isVerbose)
+		items, err = listFromFilters(store, kindsFromContext(ctx), statusesFromContext(ctx), argString, options, isVerbose)
 	}
 
 	itemPrinter.Print(items...)
@@ -42,16 +98,19 @@ func ListWithoutPrinting(ctx context.Context, argString string) ([]*Item, error)
 	var items []*Item
 	var err error
 
-	items, err = listFromTimeString(store, argString)
+	items, err = listFromTimeString(store, argString, ListOptions{})
 	if err != nil {
-		items, err = listFromFilters(store, argString, false)
+		items, err = listFromFilters(store, kindsFromContext(ctx), statusesFromContext(ctx), argString, ListOptions{}, false)
 	}
 	return items, err
 }
 
-func listFromFilters(store Store, filterString string, isVerbose bool) ([]*Item, error) {
-	p := DefaultParser(store)
-	filters, err := p.Parse(filterString)
+func listFromFilters(store Store, kinds Kinds, statuses Statuses, filterString string, options ListOptions, isVerbose bool) ([]*Item, error) {
+	filters, err := parseFilters(store, kinds, statuses, filterString)
+	if err != nil {
+		return []*Item{}, err
+	}
+	filters, err = options.apply(filters)
 	if err != nil {
 		return []*Item{}, err
 	}
@@ -67,12 +126,15 @@ func listFromFilters(store Store, filterString string, isVerbose bool) ([]*Item,
 	return store.ListFilters(filters)
 }
 
-func listFromTimeString(store Store, timeString string) ([]*Item, error) {
-	from, err := TimeParser{Input: timeString}.Parse()
+func listFromTimeString(store Store, timeString string, options ListOptions) ([]*Item, error) {
+	from, err := TimeParser{Input: timeString}.ParseRange()
 	if err != nil {
 		return []*Item{}, err
 	}
 
-	filters := []filter.Filter{NewDateFilter(filter.FilterEq, from)}
+	filters, err := options.apply([]filter.Filter{NewDateFilter(filter.FilterEq, from)})
+	if err != nil {
+		return []*Item{}, err
+	}
 	return store.ListFilters(filters)
 }
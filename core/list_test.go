@@ -86,16 +86,185 @@ func TestListFromGroup(t *testing.T) {
 		Add(ctx, strings.NewReader("same #hashtag"), "2018-08-10")
 
 		CreateGroup(ctx, "my group", "tag=#hashtag")
-		err := List(ctx, "", "my group")
+		err := List(ctx, "", "my group", ListOptions{})
 		if err != nil {
 			t.Errorf("failed to list from group")
 		}
 	})
 }
 
+func TestListFromFiltersText(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("write the release notes #work"), "now")
+		Add(ctx, strings.NewReader("notes for the next release #work"), "now")
+		Add(ctx, strings.NewReader("release notes #home"), "now")
+
+		items := getItemsFromFilters(t, store, "text~release notes,tag=#work")
+		if len(items) != 1 || items[0].Data() != "write the release notes #work" {
+			t.Errorf("item not found")
+		}
+
+		items = getItemsFromFilters(t, store, "text=release notes,tag=#work")
+		if len(items) != 2 {
+			t.Errorf("items not found")
+		}
+	})
+}
+
+func TestListFromFiltersBoolean(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("first #a"), "now")
+		Add(ctx, strings.NewReader("second #b"), "now")
+		Add(ctx, strings.NewReader("third #c"), "now")
+		Add(ctx, strings.NewReader("fourth #b"), "now")
+		Do(ctx, mostRecentItem(store).ID())
+
+		items := getItemsFromFilters(t, store, "(tag=#a or tag=#b) and not status=done")
+		if len(items) != 2 || items[0].Data() != "first #a" || items[1].Data() != "second #b" {
+			t.Errorf("items not found %v", items)
+		}
+
+		// commas join everything either side of them
+		items = getItemsFromFilters(t, store, "tag=#a or tag=#c, text=third")
+		if len(items) != 1 || items[0].Data() != "third #c" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, "not (tag=#b or tag=#c)")
+		if len(items) != 1 || items[0].Data() != "first #a" {
+			t.Errorf("items not found %v", items)
+		}
+	})
+}
+
+func TestListFromFiltersBooleanErrors(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		_, err := listFromFilters(store, DefaultKinds(), Statuses{}, "(tag=#a or tag=#b", ListOptions{}, false)
+		assert.ErrorContains(t, err, "failed to parse, missing ) at position 18")
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, "tag=#a or", ListOptions{}, false)
+		assert.ErrorContains(t, err, "failed to parse, expected a filter, not EOF at position 10")
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, "()", ListOptions{}, false)
+		assert.ErrorContains(t, err, "failed to parse, empty () at position 1")
+	})
+}
+
+func TestListFromFiltersQuoted(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("buy salt and pepper, then cook #home"), "now")
+		Add(ctx, strings.NewReader("buy salt #home"), "now")
+		Add(ctx, strings.NewReader("say \"hi\" #work"), "now")
+		CreateGroup(ctx, "home, and shopping", "tag=#home")
+
+		items := getItemsFromFilters(t, store, `text~"salt and pepper, then",tag=#home`)
+		if len(items) != 1 || items[0].Data() != "buy salt and pepper, then cook #home" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, `group='home, and shopping' and text~'pepper'`)
+		if len(items) != 1 || items[0].Data() != "buy salt and pepper, then cook #home" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, `text~"say \"hi\""`)
+		if len(items) != 1 || items[0].Data() != "say \"hi\" #work" {
+			t.Errorf("items not found %v", items)
+		}
+	})
+}
+
+func TestListFromFiltersErrorPosition(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		_, err := listFromFilters(store, DefaultKinds(), Statuses{}, "tag=#a,status=", ListOptions{}, false)
+		assert.Error(t, err, "failed to parse \"status\", missing value at position 15:\ntag=#a,status=\n              ^")
+
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, `text="unfinished`, ListOptions{}, false)
+		assert.Error(t, err, "failed to parse, missing closing \" at position 6:\ntext=\"unfinished\n     ^")
+	})
+}
+
+func TestListFromFiltersTimeRange(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("first"), "2018-08-09")
+		Add(ctx, strings.NewReader("second"), "2018-08-10")
+		Add(ctx, strings.NewReader("third"), "2018-08-12")
+
+		items := getItemsFromFilters(t, store, "time=2018-08-09..2018-08-10")
+		if len(items) != 2 || items[0].Data() != "first" || items[1].Data() != "second" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, "time=2018-08-10..")
+		if len(items) != 2 || items[0].Data() != "second" || items[1].Data() != "third" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, "time!=2018-08-09..2018-08-10")
+		if len(items) != 1 || items[0].Data() != "third" {
+			t.Errorf("items not found %v", items)
+		}
+	})
+}
+
+func TestListFromFiltersSortLimit(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("first #b"), "2018-08-10")
+		Add(ctx, strings.NewReader("second #a"), "2018-08-11")
+		Add(ctx, strings.NewReader("third #c"), "2018-08-12")
+		CreateGroup(ctx, "latest", "sort=-time,limit=1")
+
+		items := getItemsFromFilters(t, store, "sort=-time,limit=2")
+		if len(items) != 2 || items[0].Data() != "third #c" || items[1].Data() != "second #a" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, "sort=tag,offset=1")
+		if len(items) != 2 || items[0].Data() != "first #b" || items[1].Data() != "third #c" {
+			t.Errorf("items not found %v", items)
+		}
+
+		items = getItemsFromFilters(t, store, "group=latest")
+		if len(items) != 1 || items[0].Data() != "third #c" {
+			t.Errorf("items not found %v", items)
+		}
+
+		// options given with the filters replace those in them
+		items, err := listFromFilters(store, DefaultKinds(), Statuses{}, "sort=-time,limit=2", ListOptions{Sort: "tag", Limit: 1}, false)
+		assert.NilError(t, err)
+		if len(items) != 1 || items[0].Data() != "second #a" {
+			t.Errorf("items not found %v", items)
+		}
+	})
+}
+
+func TestListFromFiltersSortLimitErrors(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		_, err := listFromFilters(store, DefaultKinds(), Statuses{}, "sort=size", ListOptions{}, false)
+		assert.ErrorContains(t, err, "can't sort by \"size\", only by time, status, kind, priority, tag")
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, "limit=0", ListOptions{}, false)
+		assert.ErrorContains(t, err, "limit must be a number above 0, not \"0\"")
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, "tag=#a or limit=2", ListOptions{}, false)
+		assert.ErrorContains(t, err, "sort, limit and offset can't be used inside and, or or not")
+		_, err = listFromFilters(store, DefaultKinds(), Statuses{}, "", ListOptions{Sort: "-size"}, false)
+		assert.ErrorContains(t, err, "can't sort by \"-size\"")
+	})
+}
+
+func TestListFromGroupText(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("the migration plan"), "now")
+		Add(ctx, strings.NewReader("the rollout plan"), "now")
+
+		CreateGroup(ctx, "migrations", "text=migration")
+		items := getItemsFromFilters(t, store, "group=migrations")
+		if len(items) != 1 || items[0].Data() != "the migration plan" {
+			t.Errorf("item not found")
+		}
+	})
+}
+
 func getItemsFromFilters(t *testing.T, store Store, filterString string) []*Item {
 	var items []*Item
-	items, err := listFromFilters(store, filterString, false)
+	items, err := listFromFilters(store, DefaultKinds(), Statuses{}, filterString, ListOptions{}, false)
 	if err != nil {
 		t.Fatalf("error listing by filters")
 	}
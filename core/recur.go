@@ -0,0 +1,119 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/josler/wdid/filter"
+	"github.com/juju/ansiterm"
+)
+
+// saveFinished saves an item that's been done or skipped, along with its next occurrence if it
+// recurs, which is returned
+func saveFinished(ctx context.Context, before *Item, item *Item) (*Item, error) {
+	now := time.Now()
+	next := item.Recur(now)
+	err := withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+		if next != nil {
+			err := store.Save(next)
+			if err != nil {
+				return err
+			}
+		}
+		err := store.Save(item)
+		if err != nil {
+			return err
+		}
+		err = saveRevisions(store, before, item)
+		if err != nil || item.Open() {
+			return err
+		}
+		return stopTracking(store, item.ID(), now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Recur sets the rule a task recurs with, or stops it recurring if rule is "none"
+func Recur(ctx context.Context, id string, rule string) error {
+	item, err := FindOneOrPrint(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item.Kind() != Task {
+		return fmt.Errorf("only tasks can recur, %s is a %v", item.ID(), item.KindName())
+	}
+
+	before := *item
+	if rule == "none" {
+		item.SetRecurrence(nil)
+	} else {
+		recurrence, err := ParseRecurrence(rule)
+		if err != nil {
+			return err
+		}
+		recurrence.pinTo(item.Time())
+		item.SetRecurrence(recurrence)
+	}
+	item.touch()
+	err = saveWithRevisions(ctx, &before, item)
+	NewItemPrinter(ctx).Print(item)
+	return err
+}
+
+// ListRecurring shows the active recurrence rules, from the open task at the end of each chain
+func ListRecurring(ctx context.Context) error {
+	store := ctx.Value("store").(Store)
+	items, err := store.ListFilters([]filter.Filter{openStatusFilter()})
+	if err != nil {
+		return err
+	}
+	recurring := []*Item{}
+	for _, item := range items {
+		if item.Recurrence() != "" && item.NextID() == "" {
+			recurring = append(recurring, item)
+		}
+	}
+	fPrintRecurring(os.Stdout, GetPrintFormatFromContext(ctx), recurring...)
+	return nil
+}
+
+type JSONRecurring struct {
+	ID         string
+	Recurrence string
+	NextString string
+	Data       string
+}
+
+func fPrintRecurring(w io.Writer, printFormat PrintFormat, items ...*Item) {
+	tw := ansiterm.NewTabWriter(w, ColMinWidth, 0, 1, ' ', 0)
+	defer tw.Flush()
+
+	for _, item := range items {
+		switch printFormat {
+		case TextPrintFormat:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.ID(), item.Recurrence(), item.Time().Format(time.RFC3339), item.Data())
+		case HumanPrintFormat:
+			dataString := TrimString(strings.Split(item.Data(), "\n")[0], MaxIDLength+len(item.Recurrence())+LargestDateLen+3*ColSpacesLen)
+			fmt.Fprintf(tw, "%s\t%s\tnext %s\t%s\t\n", item.ID(), item.Recurrence(), item.Time().Format("Mon Jan 02"), dataString)
+		case JSONPrintFormat:
+			buf := bytes.Buffer{}
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			err := encoder.Encode(JSONRecurring{ID: item.ID(), Recurrence: item.Recurrence(), NextString: item.Time().Format(time.RFC3339), Data: item.Data()})
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(tw, "%s", buf.String())
+		}
+	}
+}
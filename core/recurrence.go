@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dailyFrequency    = "daily"
+	weekdaysFrequency = "weekdays"
+	weeklyFrequency   = "weekly"
+	monthlyFrequency  = "monthly"
+	everyFrequency    = "every"
+)
+
+// Recurrence is a rule for when a task happens again. Rules are written as one of:
+//
+//	daily
+//	weekdays
+//	weekly [<weekday>,...]  e.g. "weekly mon,thu", or the same weekday as the task if none are given
+//	monthly [<day>]         e.g. "monthly 15", or the same day as the task if none is given
+//	every <n> days|weeks
+//
+// with either spaces or colons between the words, so they can be written inline without spaces.
+type Recurrence struct {
+	frequency string
+	weekdays  []time.Weekday // for weekly, empty for the same weekday as the task
+	day       int            // for monthly, 0 for the same day as the task
+	days      int            // for every, how many days apart
+}
+
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	words := strings.FieldsFunc(strings.ToLower(rule), func(r rune) bool {
+		return r == ' ' || r == ':'
+	})
+	if len(words) == 0 {
+		return nil, fmt.Errorf("recurrence %q is empty", rule)
+	}
+
+	recurrence := &Recurrence{frequency: words[0]}
+	args := words[1:]
+	switch recurrence.frequency {
+	case dailyFrequency, weekdaysFrequency:
+		if len(args) == 0 {
+			return recurrence, nil
+		}
+	case weeklyFrequency:
+		tp := TimeParser{}
+		for _, arg := range args {
+			for _, name := range strings.Split(arg, ",") {
+				if name == "" {
+					continue
+				}
+				weekday, err := tp.getWeekday(name)
+				if err != nil {
+					return nil, fmt.Errorf("recurrence %q has unknown weekday %q", rule, name)
+				}
+				recurrence.weekdays = append(recurrence.weekdays, weekday)
+			}
+		}
+		return recurrence, nil
+	case monthlyFrequency:
+		if len(args) == 0 {
+			return recurrence, nil
+		}
+		day, err := strconv.Atoi(args[0])
+		if err == nil && len(args) == 1 && day >= 1 && day <= 31 {
+			recurrence.day = day
+			return recurrence, nil
+		}
+	case everyFrequency:
+		if len(args) == 0 || len(args) > 2 {
+			break
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			break
+		}
+		unit := "days"
+		if len(args) == 2 {
+			unit = args[1]
+		}
+		switch unit {
+		case "day", "days":
+			recurrence.days = n
+			return recurrence, nil
+		case "week", "weeks":
+			recurrence.days = n * 7
+			return recurrence, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to parse recurrence %q", rule)
+}
+
+func (recurrence *Recurrence) String() string {
+	switch recurrence.frequency {
+	case weeklyFrequency:
+		if len(recurrence.weekdays) == 0 {
+			return weeklyFrequency
+		}
+		names := []string{}
+		for _, weekday := range recurrence.weekdays {
+			names = append(names, strings.ToLower(weekday.String()[:3]))
+		}
+		return fmt.Sprintf("%s %s", weeklyFrequency, strings.Join(names, ","))
+	case monthlyFrequency:
+		if recurrence.day == 0 {
+			return monthlyFrequency
+		}
+		return fmt.Sprintf("%s %d", monthlyFrequency, recurrence.day)
+	case everyFrequency:
+		n, unit := recurrence.days, "day"
+		if n%7 == 0 {
+			n, unit = n/7, "week"
+		}
+		if n != 1 {
+			unit += "s"
+		}
+		return fmt.Sprintf("%s %d %s", everyFrequency, n, unit)
+	}
+	return recurrence.frequency
+}
+
+// pinTo fixes a monthly rule without a day to the day of at, so that it doesn't drift to an
+// earlier day after recurring in a shorter month
+func (recurrence *Recurrence) pinTo(at time.Time) {
+	if recurrence.frequency == monthlyFrequency && recurrence.day == 0 {
+		recurrence.day = at.Day()
+	}
+}
+
+// Next is the first occurrence after the one at, keeping the time of day
+func (recurrence *Recurrence) Next(at time.Time) time.Time {
+	switch recurrence.frequency {
+	case weekdaysFrequency:
+		next := at.AddDate(0, 0, 1)
+		for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case weeklyFrequency:
+		if len(recurrence.weekdays) == 0 {
+			return at.AddDate(0, 0, 7)
+		}
+		next := at.AddDate(0, 0, 1)
+		for !recurrence.onWeekday(next.Weekday()) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case monthlyFrequency:
+		day := recurrence.day
+		if day == 0 {
+			day = at.Day()
+		}
+		next := dayOfMonth(at, day)
+		if !next.After(at) {
+			next = dayOfMonth(time.Date(at.Year(), at.Month()+1, 1, at.Hour(), at.Minute(), at.Second(), 0, at.Location()), day)
+		}
+		return next
+	case everyFrequency:
+		return at.AddDate(0, 0, recurrence.days)
+	}
+	return at.AddDate(0, 0, 1)
+}
+
+// NextAfter is the first occurrence after the one at that isn't before the day of now, so
+// tasks finished late don't recur in the past
+func (recurrence *Recurrence) NextAfter(at time.Time, now time.Time) time.Time {
+	today := TimeParser{}.startOfDay(now)
+	next := recurrence.Next(at)
+	for next.Before(today) {
+		next = recurrence.Next(next)
+	}
+	return next
+}
+
+func (recurrence *Recurrence) onWeekday(weekday time.Weekday) bool {
+	for _, day := range recurrence.weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// dayOfMonth is day in the same month as t, or the last day of the month if it's shorter
+func dayOfMonth(t time.Time, day int) time.Time {
+	lastDay := TimeParser{}.endOfMonth(t).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(t.Year(), t.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
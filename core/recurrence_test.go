@@ -0,0 +1,90 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	for input, expected := range map[string]string{
+		"daily":            "daily",
+		"Weekdays":         "weekdays",
+		"weekly":           "weekly",
+		"weekly mon,thurs": "weekly mon,thu",
+		"weekly:mon:fri":   "weekly mon,fri",
+		"monthly 15":       "monthly 15",
+		"every 3 days":     "every 3 days",
+		"every:2:weeks":    "every 2 weeks",
+		"every 14":         "every 2 weeks",
+		"every 1 weeks":    "every 1 week",
+		"every 7 days":     "every 1 week",
+		"every 1":          "every 1 day",
+	} {
+		recurrence, err := ParseRecurrence(input)
+		assert.NilError(t, err, input)
+		assert.Equal(t, recurrence.String(), expected)
+	}
+}
+
+func TestParseRecurrenceError(t *testing.T) {
+	_, err := ParseRecurrence("")
+	assert.Error(t, err, "recurrence \"\" is empty")
+	_, err = ParseRecurrence("weekly funday")
+	assert.Error(t, err, "recurrence \"weekly funday\" has unknown weekday \"funday\"")
+	for _, input := range []string{"hourly", "daily 2", "monthly 32", "every 0 days", "every 3 months"} {
+		_, err = ParseRecurrence(input)
+		assert.ErrorContains(t, err, "failed to parse recurrence", input)
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	friday := timeAt("2018-03-23 09:00:00 -0400 EDT")
+	for rule, expected := range map[string]string{
+		"daily":          "2018-03-24 09:00:00 -0400 EDT",
+		"weekdays":       "2018-03-26 09:00:00 -0400 EDT",
+		"weekly":         "2018-03-30 09:00:00 -0400 EDT",
+		"weekly tue,sat": "2018-03-24 09:00:00 -0400 EDT",
+		"monthly 10":     "2018-04-10 09:00:00 -0400 EDT",
+		"monthly 31":     "2018-03-31 09:00:00 -0400 EDT",
+		"every 3 days":   "2018-03-26 09:00:00 -0400 EDT",
+	} {
+		recurrence, _ := ParseRecurrence(rule)
+		assert.Assert(t, recurrence.Next(friday).Equal(timeAt(expected)), "%s gave %v", rule, recurrence.Next(friday))
+	}
+
+	// clamped to the end of shorter months
+	recurrence, _ := ParseRecurrence("monthly 31")
+	next := recurrence.Next(timeAt("2018-03-31 09:00:00 -0400 EDT"))
+	assert.Assert(t, next.Equal(timeAt("2018-04-30 09:00:00 -0400 EDT")), next)
+}
+
+func TestRecurrenceNextAfter(t *testing.T) {
+	recurrence, _ := ParseRecurrence("weekly")
+	next := recurrence.NextAfter(timeAt("2018-03-02 09:00:00 -0400 EDT"), timeAt("2018-03-23 17:00:00 -0400 EDT"))
+	assert.Assert(t, next.Equal(timeAt("2018-03-23 09:00:00 -0400 EDT")), next)
+}
+
+func TestItemRecur(t *testing.T) {
+	at := time.Now().Add(-time.Hour)
+	item := NewTask("weekly report #admin", at)
+	recurrence, _ := ParseRecurrence("daily")
+	item.SetRecurrence(recurrence)
+	item.SetPriority(HighPriority)
+	item.SetDue(at.Add(2 * time.Hour))
+
+	assert.Assert(t, item.Recur(time.Now()) == nil) // still waiting
+
+	item.Do()
+	next := item.Recur(time.Now())
+	assert.Equal(t, next.Data(), item.Data())
+	assert.Equal(t, next.Recurrence(), "daily")
+	assert.Equal(t, next.Priority(), HighPriority)
+	assert.Assert(t, next.Time().Equal(at.AddDate(0, 0, 1)))
+	assert.Assert(t, next.Due().Equal(at.AddDate(0, 0, 1).Add(2*time.Hour)))
+	assert.Equal(t, item.NextID(), next.ID())
+	assert.Equal(t, next.PreviousID(), item.ID())
+
+	assert.Assert(t, item.Recur(time.Now()) == nil) // already recurred
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Revision records a single field of an item changing
+type Revision struct {
+	internalID string
+	ItemID     string
+	At         time.Time
+	Field      string
+	Old        string
+	New        string
+}
+
+func NewRevision(itemID string, at time.Time, field string, old string, new string) *Revision {
+	return &Revision{ItemID: itemID, At: at, Field: field, Old: old, New: new}
+}
+
+// revisionsBetween returns a revision for every field that differs between before and after
+func revisionsBetween(before *Item, after *Item, at time.Time) []*Revision {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"data", before.Data(), after.Data()},
+		{"time", before.Time().Format(time.RFC3339), after.Time().Format(time.RFC3339)},
+		{"status", before.Status(), after.Status()},
+		{"kind", before.KindName(), after.KindName()},
+		{"due", formatOptionalTime(before.Due()), formatOptionalTime(after.Due())},
+		{"priority", before.Priority().String(), after.Priority().String()},
+		{"recurrence", before.Recurrence(), after.Recurrence()},
+		{"next_id", before.NextID(), after.NextID()},
+		{"previous_id", before.PreviousID(), after.PreviousID()},
+	}
+
+	revisions := []*Revision{}
+	for _, field := range fields {
+		if field.old != field.new {
+			revisions = append(revisions, NewRevision(after.ID(), at, field.name, field.old, field.new))
+		}
+	}
+	return revisions
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// saveWithRevisions saves item, along with a revision for each field changed since before
+func saveWithRevisions(ctx context.Context, before *Item, item *Item) error {
+	return withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+		err := store.Save(item)
+		if err != nil {
+			return err
+		}
+		return saveRevisions(store, before, item)
+	})
+}
+
+func saveRevisions(store Store, before *Item, item *Item) error {
+	for _, revision := range revisionsBetween(before, item, time.Now()) {
+		err := store.SaveRevision(revision)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func History(ctx context.Context, idString string) error {
+	store := ctx.Value("store").(Store)
+	item, err := FindOneOrPrint(ctx, idString)
+	if err == ErrNotFound {
+		// removed items keep their history
+		items, trashErr := store.FindAllTrash(idString)
+		item, err = oneOrPrint(ctx, items, trashErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	revisions, err := store.ListRevisions(item.ID())
+	if err != nil {
+		return err
+	}
+	NewRevisionPrinter(ctx).Print(revisions...)
+	return nil
+}
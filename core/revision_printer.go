@@ -0,0 +1,88 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/juju/ansiterm"
+)
+
+type RevisionPrinter struct {
+	oldColor color.Attribute
+	newColor color.Attribute
+
+	PrintFormat PrintFormat
+}
+
+func NewRevisionPrinter(ctx context.Context) *RevisionPrinter {
+	return &RevisionPrinter{
+		oldColor:    color.FgRed,
+		newColor:    color.FgGreen,
+		PrintFormat: GetPrintFormatFromContext(ctx),
+	}
+}
+
+func (rp *RevisionPrinter) Print(revisions ...*Revision) {
+	rp.FPrint(os.Stdout, revisions...)
+}
+
+func (rp *RevisionPrinter) FPrint(w io.Writer, revisions ...*Revision) {
+	tw := ansiterm.NewTabWriter(w, ColMinWidth, 0, 1, ' ', 0)
+	defer tw.Flush()
+
+	for _, revision := range revisions {
+		switch rp.PrintFormat {
+		case TextPrintFormat:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", revision.ItemID, revision.At.Format(time.RFC3339), revision.Field, revision.Old, revision.New)
+		case HumanPrintFormat:
+			rp.fPrintRevisionHuman(tw, revision)
+		case JSONPrintFormat:
+			rp.fPrintRevisionJSON(tw, revision)
+		}
+	}
+}
+
+func (rp *RevisionPrinter) fPrintRevisionHuman(w io.Writer, revision *Revision) {
+	oldColor := color.New(rp.oldColor)
+	oldColor.EnableColor()
+	newColor := color.New(rp.newColor)
+	newColor.EnableColor()
+
+	// only the first line of multi-line data, to keep one revision per line
+	old := strings.Split(revision.Old, "\n")[0]
+	new := strings.Split(revision.New, "\n")[0]
+	fmt.Fprintf(w, "%s\t%s\t%s → %s\t\n", revision.At.Format("Mon, 02 Jan 2006 15:04:05"), revision.Field, oldColor.Sprint(old), newColor.Sprint(new))
+}
+
+type JSONRevision struct {
+	ItemID     string
+	TimeString string
+	Field      string
+	Old        string
+	New        string
+}
+
+func (rp *RevisionPrinter) fPrintRevisionJSON(w io.Writer, revision *Revision) {
+	jsonRevision := JSONRevision{
+		ItemID:     revision.ItemID,
+		TimeString: revision.At.Format(time.RFC3339),
+		Field:      revision.Field,
+		Old:        revision.Old,
+		New:        revision.New,
+	}
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(jsonRevision)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "%s", buf.String())
+}
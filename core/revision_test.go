@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestHistoryRecordsChanges(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		err := Edit(ctx, found.ID(), strings.NewReader("my edited item"), "2018-04-03")
+		assert.NilError(t, err)
+		err = Do(ctx, found.ID())
+		assert.NilError(t, err)
+
+		revisions, err := store.ListRevisions(found.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, len(revisions), 3)
+		assert.Equal(t, revisions[0].Field, "data")
+		assert.Equal(t, revisions[0].Old, "my new item")
+		assert.Equal(t, revisions[0].New, "my edited item")
+		assert.Equal(t, revisions[1].Field, "time")
+		assert.Equal(t, revisions[2].Field, "status")
+		assert.Equal(t, revisions[2].Old, WaitingStatus)
+		assert.Equal(t, revisions[2].New, DoneStatus)
+	})
+}
+
+func TestHistoryRecordsBump(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		err := Bump(ctx, found.ID(), "2018-04-03")
+		assert.NilError(t, err)
+
+		revisions, err := store.ListRevisions(found.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, len(revisions), 2)
+		assert.Equal(t, revisions[0].Field, "status")
+		assert.Equal(t, revisions[0].New, BumpedStatus)
+		assert.Equal(t, revisions[1].Field, "next_id")
+	})
+}
+
+func TestHistoryUnchangedEdit(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		err := Edit(ctx, found.ID(), strings.NewReader("my new item"), "")
+		assert.NilError(t, err)
+
+		revisions, err := store.ListRevisions(found.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, len(revisions), 0)
+	})
+}
+
+func TestHistoryOfTrashedItem(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+		Do(ctx, found.ID())
+		Rm(ctx, found.ID(), false)
+
+		err := History(ctx, found.ID())
+		assert.NilError(t, err)
+	})
+}
+
+func TestRevisionPrinterText(t *testing.T) {
+	at := time.Date(2018, 4, 2, 10, 0, 0, 0, time.UTC)
+	printer := &RevisionPrinter{PrintFormat: TextPrintFormat}
+	buf := &bytes.Buffer{}
+	printer.FPrint(buf, NewRevision("abc123", at, "status", "waiting", "done"))
+	assert.Equal(t, buf.String(), "abc123\t2018-04-02T10:00:00Z\tstatus\twaiting\tdone\n")
+}
+
+func TestRevisionPrinterJSON(t *testing.T) {
+	at := time.Date(2018, 4, 2, 10, 0, 0, 0, time.UTC)
+	printer := &RevisionPrinter{PrintFormat: JSONPrintFormat}
+	buf := &bytes.Buffer{}
+	printer.FPrint(buf, NewRevision("abc123", at, "data", "<old>", "new"))
+	assert.Equal(t, buf.String(), `{"ItemID":"abc123","TimeString":"2018-04-02T10:00:00Z","Field":"data","Old":"<old>","New":"new"}`+"\n")
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 )
 
-func Rm(ctx context.Context, idString string) error {
+// Rm moves an item to the trash, or removes it permanently with force
+func Rm(ctx context.Context, idString string, force bool) error {
 	item, err := FindOneOrPrint(ctx, idString)
 	if err != nil {
 		return err
 	}
 	NewItemPrinter(ctx).Print(item)
 
-	itemCreator := &ItemCreator{ctx: ctx}
-	return itemCreator.Delete(item)
+	if force {
+		itemCreator := &ItemCreator{ctx: ctx}
+		return itemCreator.Delete(item)
+	}
+	store := ctx.Value("store").(Store)
+	return store.Trash(item)
 }
@@ -12,7 +12,7 @@ func TestRm(t *testing.T) {
 	contextWithStore(func(ctx context.Context, store Store) {
 		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
 		found := mostRecentItem(store)
-		err := Rm(ctx, found.ID())
+		err := Rm(ctx, found.ID(), false)
 		if err != nil {
 			t.Errorf("item not removed")
 		}
@@ -20,6 +20,68 @@ func TestRm(t *testing.T) {
 		if err == nil {
 			t.Errorf("item not removed")
 		}
+		trashed, _ := store.FindAllTrash(found.ID())
+		if len(trashed) != 1 {
+			t.Errorf("item not moved to trash")
+		}
+	})
+}
+
+func TestRmForce(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+		err := Rm(ctx, found.ID(), true)
+		if err != nil {
+			t.Errorf("item not removed")
+		}
+		_, err = store.FindAllTrash(found.ID())
+		if err != ErrNotFound {
+			t.Errorf("item moved to trash")
+		}
+	})
+}
+
+func TestRestore(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+		Rm(ctx, found.ID(), false)
+
+		err := Restore(ctx, found.ID())
+		if err != nil {
+			t.Errorf("item not restored %v", err)
+		}
+		err = Show(ctx, found.ID(), false)
+		if err != nil {
+			t.Errorf("item not restored")
+		}
+	})
+}
+
+func TestEmptyTrashOlderThan(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+		Rm(ctx, found.ID(), false)
+
+		err := EmptyTrash(ctx, "2018-04-02")
+		if err != nil {
+			t.Errorf("failed to empty trash %v", err)
+		}
+		trashed, _ := store.ListTrash()
+		if len(trashed) != 1 {
+			t.Errorf("recently trashed item removed")
+		}
+
+		err = EmptyTrash(ctx, "")
+		if err != nil {
+			t.Errorf("failed to empty trash %v", err)
+		}
+		trashed, _ = store.ListTrash()
+		if len(trashed) != 0 {
+			t.Errorf("trash not emptied")
+		}
 	})
 }
 
@@ -36,7 +98,7 @@ func TestRmMultiMatching(t *testing.T) {
 			t.Errorf("failed to save!")
 		}
 
-		err = Rm(ctx, found.ID()[:3])
+		err = Rm(ctx, found.ID()[:3], false)
 		if err == nil {
 			t.Errorf("rm didnt error as it should")
 		}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+
+	"github.com/josler/wdid/parser"
 )
 
 func Show(ctx context.Context, idString string, showConnected bool) error {
@@ -24,7 +26,8 @@ func Show(ctx context.Context, idString string, showConnected bool) error {
 func getValidConnections(ctx context.Context, item *Item) []*Item {
 	filteredConnections := []*Item{}
 	for _, connection := range item.Connections() {
-		found, err := FindOneOrPrint(ctx, connection)
+		_, id := parser.SplitConnection(connection)
+		found, err := FindOneOrPrint(ctx, id)
 		if err != nil {
 			continue // invalid connection
 		}
@@ -3,13 +3,16 @@ package core
 import "context"
 
 func Skip(ctx context.Context, id string) error {
-	store := ctx.Value("store").(Store)
 	item, err := FindOneOrPrint(ctx, id)
 	if err != nil {
 		return err
 	}
+	before := *item
 	item.Skip()
-	err = store.WithContext(ctx).Save(item)
+	next, err := saveFinished(ctx, &before, item)
 	NewItemPrinter(ctx).Print(item)
+	if next != nil {
+		NewItemPrinter(ctx).Print(next)
+	}
 	return err
 }
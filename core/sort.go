@@ -0,0 +1,341 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/josler/wdid/filter"
+)
+
+// the fields items can be sorted by
+const (
+	TimeSort     = "time"
+	StatusSort   = "status"
+	KindSort     = "kind"
+	PrioritySort = "priority"
+	TagSort      = "tag"
+)
+
+var sortFields = []string{TimeSort, StatusSort, KindSort, PrioritySort, TagSort}
+
+// SortFilter orders the items listed by one of their fields, rather than matching them. It's
+// written as "sort=<field>", or "sort=-<field>" to sort in descending order. Items are otherwise
+// listed by time.
+type SortFilter struct {
+	field      string
+	descending bool
+}
+
+func NewSortFilter(field string, descending bool) *SortFilter {
+	return &SortFilter{field: field, descending: descending}
+}
+
+func SortFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	if comparison != filter.FilterEq {
+		return nil, fmt.Errorf("sort filter only supports =, not %v", comparison)
+	}
+	field := strings.TrimPrefix(val, "-")
+	for _, sortField := range sortFields {
+		if field == sortField {
+			return NewSortFilter(field, field != val), nil
+		}
+	}
+	return nil, fmt.Errorf("can't sort by %q, only by %s", val, strings.Join(sortFields, ", "))
+}
+
+// Match matches everything, sorting is done by the store
+func (sortFilter *SortFilter) Match(matchable filter.Matchable) (bool, error) {
+	return true, nil
+}
+
+func (sortFilter *SortFilter) String() string {
+	if sortFilter.descending {
+		return fmt.Sprintf("Sort by %s, descending", sortFilter.field)
+	}
+	return fmt.Sprintf("Sort by %s", sortFilter.field)
+}
+
+// compare orders a before b with a negative result, after it with a positive one
+func (sortFilter *SortFilter) compare(a *Item, b *Item) int {
+	result := 0
+	switch sortFilter.field {
+	case TimeSort:
+		result = compareInts(a.Time().Unix(), b.Time().Unix())
+	case StatusSort:
+		result = compareInts(int64(statusRank(a.Status())), int64(statusRank(b.Status())))
+		if result == 0 {
+			result = strings.Compare(a.Status(), b.Status())
+		}
+	case KindSort:
+		result = strings.Compare(a.KindName(), b.KindName())
+	case PrioritySort:
+		result = compareInts(int64(priorityRank(a.Priority())), int64(priorityRank(b.Priority())))
+	case TagSort:
+		aTag, bTag := firstTagName(a), firstTagName(b)
+		switch {
+		case aTag == bTag:
+		case aTag == "":
+			result = 1
+		case bTag == "":
+			result = -1
+		default:
+			result = strings.Compare(aTag, bTag)
+		}
+	}
+	if sortFilter.descending {
+		return -result
+	}
+	return result
+}
+
+// statusRank puts open tasks first, waiting before those in a status declared in the config,
+// then finished tasks, then notes
+func statusRank(status string) int {
+	switch status {
+	case WaitingStatus:
+		return 0
+	case DoneStatus:
+		return 2
+	case SkippedStatus:
+		return 3
+	case BumpedStatus:
+		return 4
+	case NoStatus:
+		return 5
+	}
+	return 1
+}
+
+// priorityRank puts the most urgent first, and items without a priority last
+func priorityRank(priority Priority) int {
+	if priority == NoPriority {
+		return int(LowestPriority) + 1
+	}
+	return int(priority)
+}
+
+// firstTagName is the first of the item's tags alphabetically, empty if it has none
+func firstTagName(item *Item) string {
+	tags := item.Tags()
+	if len(tags) == 0 {
+		return ""
+	}
+	return tags[0].Name()
+}
+
+func compareInts(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// LimitFilter limits how many items are listed, rather than matching them. It's written as "limit=<n>".
+type LimitFilter struct {
+	limit int
+}
+
+func NewLimitFilter(limit int) *LimitFilter {
+	return &LimitFilter{limit: limit}
+}
+
+func LimitFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	if comparison != filter.FilterEq {
+		return nil, fmt.Errorf("limit filter only supports =, not %v", comparison)
+	}
+	limit, err := strconv.Atoi(val)
+	if err != nil || limit < 1 {
+		return nil, fmt.Errorf("limit must be a number above 0, not %q", val)
+	}
+	return NewLimitFilter(limit), nil
+}
+
+// Match matches everything, limiting is done by the store
+func (limitFilter *LimitFilter) Match(matchable filter.Matchable) (bool, error) {
+	return true, nil
+}
+
+func (limitFilter *LimitFilter) String() string {
+	return fmt.Sprintf("Limit to %d", limitFilter.limit)
+}
+
+// OffsetFilter skips the first items that would be listed, rather than matching them. It's
+// written as "offset=<n>".
+type OffsetFilter struct {
+	offset int
+}
+
+func NewOffsetFilter(offset int) *OffsetFilter {
+	return &OffsetFilter{offset: offset}
+}
+
+func OffsetFilterFn(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+	if comparison != filter.FilterEq {
+		return nil, fmt.Errorf("offset filter only supports =, not %v", comparison)
+	}
+	offset, err := strconv.Atoi(val)
+	if err != nil || offset < 0 {
+		return nil, fmt.Errorf("offset must be a number, not %q", val)
+	}
+	return NewOffsetFilter(offset), nil
+}
+
+// Match matches everything, skipping items is done by the store
+func (offsetFilter *OffsetFilter) Match(matchable filter.Matchable) (bool, error) {
+	return true, nil
+}
+
+func (offsetFilter *OffsetFilter) String() string {
+	return fmt.Sprintf("Offset by %d", offsetFilter.offset)
+}
+
+// listOptions are how the items matching filters are sorted and paged
+type listOptions struct {
+	sorts  []*SortFilter
+	limit  int // 0 if there's no limit
+	offset int
+}
+
+// findListOptions takes the sort, limit and offset filters out of filters. A group the items
+// must be in brings its own, unless they're given outside it too. Sorts all apply in order,
+// while for limit and offset the last one wins.
+func findListOptions(filters []filter.Filter) (listOptions, []filter.Filter) {
+	options := listOptions{}
+	groupOptions := listOptions{}
+	hasOffset := false
+	rest := []filter.Filter{}
+	for _, f := range filters {
+		switch typed := f.(type) {
+		case *SortFilter:
+			options.sorts = append(options.sorts, typed)
+		case *LimitFilter:
+			options.limit = typed.limit
+		case *OffsetFilter:
+			options.offset = typed.offset
+			hasOffset = true
+		case *GroupFilter:
+			if typed.comparison == filter.FilterEq {
+				groupOptions.add(typed.options)
+			}
+			rest = append(rest, f)
+		default:
+			rest = append(rest, f)
+		}
+	}
+
+	if len(options.sorts) == 0 {
+		options.sorts = groupOptions.sorts
+	}
+	if options.limit == 0 {
+		options.limit = groupOptions.limit
+	}
+	if !hasOffset {
+		options.offset = groupOptions.offset
+	}
+	return options, rest
+}
+
+// add adds other options to these, with other's limit and offset taking precedence
+func (options *listOptions) add(other listOptions) {
+	options.sorts = append(options.sorts, other.sorts...)
+	if other.limit != 0 {
+		options.limit = other.limit
+	}
+	if other.offset != 0 {
+		options.offset = other.offset
+	}
+}
+
+// byTime is true if the items are only sorted by time, the order stores keep them in already
+func (options listOptions) byTime() bool {
+	for _, sortFilter := range options.sorts {
+		if sortFilter.field != TimeSort {
+			return false
+		}
+	}
+	return true
+}
+
+// descendingTime is true if any sort is by -time, in which case items that sort the same are
+// left in reverse time order rather than time order
+func (options listOptions) descendingTime() bool {
+	for _, sortFilter := range options.sorts {
+		if sortFilter.field == TimeSort && sortFilter.descending {
+			return true
+		}
+	}
+	return false
+}
+
+// paged is true if there's a limit or offset
+func (options listOptions) paged() bool {
+	return options.limit != 0 || options.offset != 0
+}
+
+// sort sorts items that are in time order by the sorts
+func (options listOptions) sort(items []*Item) {
+	if len(options.sorts) == 0 {
+		return
+	}
+	if options.descendingTime() {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, sortFilter := range options.sorts {
+			result := sortFilter.compare(items[i], items[j])
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+}
+
+// page is the items after the offset, up to the limit
+func (options listOptions) page(items []*Item) []*Item {
+	if options.offset >= len(items) {
+		return []*Item{}
+	}
+	items = items[options.offset:]
+	if options.limit != 0 && options.limit < len(items) {
+		items = items[:options.limit]
+	}
+	return items
+}
+
+// checkListOptions makes sure sort, limit and offset filters aren't used inside and, or or not,
+// where they couldn't apply
+func checkListOptions(filters []filter.Filter) error {
+	for _, f := range filters {
+		var inner []filter.Filter
+		switch typed := f.(type) {
+		case *filter.And:
+			inner = typed.Filters
+		case *filter.Or:
+			inner = typed.Filters
+		case *filter.Not:
+			inner = []filter.Filter{typed.Filter}
+		default:
+			continue
+		}
+		for _, innerFilter := range inner {
+			switch innerFilter.(type) {
+			case *SortFilter, *LimitFilter, *OffsetFilter:
+				return errors.New("sort, limit and offset can't be used inside and, or or not")
+			}
+		}
+		err := checkListOptions(inner)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/josler/wdid/config"
+	"github.com/josler/wdid/filter"
+)
+
+// StatusDefinition is a status declared in the config, which tasks can be in between waiting
+// and being finished
+type StatusDefinition struct {
+	Name  string
+	Glyph string
+	Color color.Attribute
+	From  []string // the statuses tasks can change to this one from
+}
+
+// Statuses are the statuses declared in the config, by name
+type Statuses map[string]*StatusDefinition
+
+var builtInStatuses = []string{NoStatus, WaitingStatus, DoneStatus, SkippedStatus, BumpedStatus}
+
+func isBuiltInStatus(name string) bool {
+	for _, status := range builtInStatuses {
+		if status == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewStatuses are the statuses declared in the config
+func NewStatuses(configStatuses []config.ConfigStatus) (Statuses, error) {
+	statuses := Statuses{}
+	for _, configStatus := range configStatuses {
+		if configStatus.Name == "" {
+			return nil, errors.New("status must have a name")
+		}
+		if _, ok := statuses[configStatus.Name]; ok || isBuiltInStatus(configStatus.Name) {
+			return nil, fmt.Errorf("status %q is already declared", configStatus.Name)
+		}
+		definition := &StatusDefinition{Name: configStatus.Name, Glyph: configStatus.Glyph, Color: color.FgCyan, From: configStatus.From}
+		if definition.Glyph == "" {
+			definition.Glyph = "◆"
+		}
+		if configStatus.Color != "" {
+			c, ok := configColors[configStatus.Color]
+			if !ok {
+				return nil, fmt.Errorf("status %q has unknown color %q", configStatus.Name, configStatus.Color)
+			}
+			definition.Color = c
+		}
+		if len(definition.From) == 0 {
+			definition.From = []string{WaitingStatus}
+		}
+		statuses[configStatus.Name] = definition
+	}
+
+	for _, definition := range statuses {
+		for _, from := range definition.From {
+			if !statuses.Valid(from) || from == NoStatus || from == BumpedStatus {
+				return nil, fmt.Errorf("status %q can't change from %q", definition.Name, from)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// Valid is true for the built in statuses and those declared in the config
+func (statuses Statuses) Valid(name string) bool {
+	_, ok := statuses[name]
+	return ok || isBuiltInStatus(name)
+}
+
+// CanChange checks an item can change from one status to another. Tasks can always be done or
+// skipped, and go back to waiting from a status declared in the config, but they can only move
+// to a status declared in the config from the statuses it allows.
+func (statuses Statuses) CanChange(from string, to string) error {
+	if !statuses.Valid(to) {
+		return fmt.Errorf("status %q not found", to)
+	}
+	switch {
+	case from == NoStatus:
+		return errors.New("only items with a status can change status")
+	case from == BumpedStatus:
+		return errors.New("can't change the status of a bumped item")
+	case from == to:
+		return fmt.Errorf("already %s", to)
+	}
+
+	switch to {
+	case DoneStatus, SkippedStatus:
+		return nil
+	case WaitingStatus:
+		if _, ok := statuses[from]; ok {
+			return nil
+		}
+	case NoStatus, BumpedStatus:
+		// only set by adding notes and bumping
+	default:
+		for _, allowed := range statuses[to].From {
+			if allowed == from {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("can't change status from %s to %s", from, to)
+}
+
+// statusesFromContext are the statuses declared in the config, if there are any
+func statusesFromContext(ctx context.Context) Statuses {
+	statuses, ok := ctx.Value("statuses").(Statuses)
+	if !ok {
+		return Statuses{}
+	}
+	return statuses
+}
+
+// openStatusFilter matches tasks that haven't been finished, which are waiting or in a status
+// declared in the config
+func openStatusFilter() *StatusFilter {
+	return NewStatusFilter(filter.FilterNe, NoStatus, DoneStatus, SkippedStatus, BumpedStatus)
+}
+
+// ChangeStatus changes the status of a task, if the change is allowed
+func ChangeStatus(ctx context.Context, id string, status string) error {
+	item, err := FindOneOrPrint(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = statusesFromContext(ctx).CanChange(item.Status(), status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", item.ID(), err)
+	}
+
+	before := *item
+	switch status {
+	case DoneStatus:
+		warnIfBlocked(ctx, item)
+		item.Do()
+	case SkippedStatus:
+		item.Skip()
+	default:
+		item.status = status
+		item.touch()
+	}
+	next, err := saveFinished(ctx, &before, item)
+	NewItemPrinter(ctx).Print(item)
+	if next != nil {
+		NewItemPrinter(ctx).Print(next)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/josler/wdid/config"
+	"gotest.tools/assert"
+)
+
+func TestNewStatuses(t *testing.T) {
+	statuses, err := NewStatuses([]config.ConfigStatus{{Name: "in-progress"}, {Name: "review", From: []string{"in-progress"}}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, statuses["in-progress"].From, []string{WaitingStatus})
+	assert.Assert(t, statuses.Valid("review"))
+	assert.Assert(t, statuses.Valid(DoneStatus))
+
+	_, err = NewStatuses([]config.ConfigStatus{{Name: "done"}})
+	assert.Error(t, err, `status "done" is already declared`)
+	_, err = NewStatuses([]config.ConfigStatus{{Name: "review", From: []string{"in-progress"}}})
+	assert.Error(t, err, `status "review" can't change from "in-progress"`)
+}
+
+func TestCanChange(t *testing.T) {
+	statuses, _ := NewStatuses([]config.ConfigStatus{{Name: "in-progress"}, {Name: "review", From: []string{"in-progress"}}})
+	assert.NilError(t, statuses.CanChange(WaitingStatus, "in-progress"))
+	assert.NilError(t, statuses.CanChange("in-progress", "review"))
+	assert.NilError(t, statuses.CanChange("review", WaitingStatus))
+	assert.NilError(t, statuses.CanChange("review", DoneStatus))
+	assert.Error(t, statuses.CanChange(WaitingStatus, "review"), "can't change status from waiting to review")
+	assert.Error(t, statuses.CanChange(DoneStatus, WaitingStatus), "can't change status from done to waiting")
+	assert.Error(t, statuses.CanChange(NoStatus, DoneStatus), "only items with a status can change status")
+	assert.Error(t, statuses.CanChange(WaitingStatus, "archived"), `status "archived" not found`)
+}
+
+func TestChangeStatus(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		statuses, _ := NewStatuses([]config.ConfigStatus{{Name: "in-progress"}, {Name: "review", From: []string{"in-progress"}}})
+		ctx = context.WithValue(ctx, "statuses", statuses)
+		Add(ctx, strings.NewReader("write the release notes"), "now")
+		found := mostRecentItem(store)
+
+		err := ChangeStatus(ctx, found.ID(), "review")
+		assert.Error(t, err, fmt.Sprintf("%s: can't change status from waiting to review", found.ID()))
+
+		err = ChangeStatus(ctx, found.ID(), "in-progress")
+		assert.NilError(t, err)
+		items, err := ListWithoutPrinting(ctx, "status=in-progress")
+		assert.NilError(t, err)
+		assert.Equal(t, len(items), 1)
+		assert.Assert(t, items[0].Open())
+
+		err = ChangeStatus(ctx, found.ID(), DoneStatus)
+		assert.NilError(t, err)
+		items, _ = store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), DoneStatus)
+		assert.Assert(t, !items[0].CompletedAt().IsZero())
+	})
+}
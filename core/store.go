@@ -2,20 +2,41 @@ package core
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
 )
 
 const (
 	MaxIDLength = 6
 )
 
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+)
+
 type Store interface {
 	ItemStore
 	TagStore
 	GroupStore
+	TrashStore
+	RevisionStore
+	OperationStore
+	IntervalStore
 
 	WithContext(ctx context.Context) Store
+
+	// Open holds the underlying storage open until Close, so that a whole command
+	// shares a single handle rather than reopening it for each operation
+	Open() error
+	Close() error
+
+	// Transaction runs f against a store where everything is applied atomically,
+	// or not at all if f returns an error. Only use tx inside f.
+	Transaction(f func(tx Store) error) error
 }
 
 type ItemStore interface {
@@ -25,6 +46,38 @@ type ItemStore interface {
 	ListFilters(filters []filter.Filter) ([]*Item, error)
 }
 
+// TrashStore holds items removed with Trash. Trashed items are hidden from the ItemStore
+// methods until they are restored.
+type TrashStore interface {
+	Trash(item *Item) error
+	ListTrash() ([]*Item, error) // ordered by when they were trashed
+	FindAllTrash(id string) ([]*Item, error)
+	Restore(item *Item) error
+	DeleteTrash(item *Item) error
+}
+
+// RevisionStore keeps the history of changes to items
+type RevisionStore interface {
+	SaveRevision(revision *Revision) error
+	ListRevisions(itemID string) ([]*Revision, error) // oldest first
+}
+
+// OperationStore keeps the journal of operations that can be undone
+type OperationStore interface {
+	SaveOperation(operation *Operation) error
+	ListOperations() ([]*Operation, error) // newest first
+	DeleteOperation(operation *Operation) error
+}
+
+// IntervalStore keeps the time tracked against items
+type IntervalStore interface {
+	SaveInterval(interval *Interval) error                          // adds the interval, or updates it if already saved
+	ListIntervals(itemID string) ([]*Interval, error)               // oldest first
+	ListRunningIntervals() ([]*Interval, error)                     // oldest first
+	ListIntervalsBetween(start, end time.Time) ([]*Interval, error) // running at some point between start and end, oldest first
+	DeleteInterval(interval *Interval) error
+}
+
 type TagStore interface {
 	FindTag(name string) (*Tag, error)
 	SaveTag(tag *Tag) error
@@ -37,3 +90,172 @@ type GroupStore interface {
 	ListGroups() ([]*Group, error)
 	FindGroupByName(name string) (*Group, error)
 }
+
+// withTransaction runs f in a store transaction, with the transaction's store swapped into
+// the context so that everything f calls uses it
+func withTransaction(ctx context.Context, f func(ctx context.Context) error) error {
+	store := ctx.Value("store").(Store)
+	return store.Transaction(func(tx Store) error {
+		return f(context.WithValue(ctx, "store", tx))
+	})
+}
+
+// findFirstDateFilter splits out the first date filter matching items within its range, which
+// stores can use as a range to limit the items they need to search over
+func findFirstDateFilter(filters []filter.Filter) (*DateFilter, []filter.Filter) {
+	for i, f := range filters {
+		switch df := f.(type) {
+		case *DateFilter:
+			if df.comparison == filter.FilterNe {
+				continue
+			}
+			rest := append([]filter.Filter{}, filters[:i]...)
+			rest = append(rest, filters[i+1:]...)
+			return df, rest
+		}
+	}
+	return nil, filters
+}
+
+// findTagEqFilters finds the tag filters that an item must match, which stores can use with
+// a tag index to limit the items they need to search over
+func findTagEqFilters(filters []filter.Filter) []*TagFilter {
+	tagFilters := []*TagFilter{}
+	for _, f := range filters {
+		if tf, ok := f.(*TagFilter); ok && tf.comparison == filter.FilterEq {
+			tagFilters = append(tagFilters, tf)
+		}
+	}
+	return tagFilters
+}
+
+// findConnectionTypes returns the distinct connection types that connection filters require every
+// matching item to have
+func findConnectionTypes(filters []filter.Filter) []string {
+	types := []string{}
+	seen := map[string]bool{}
+	for _, f := range filters {
+		if cf, ok := f.(*ConnectionFilter); ok && !seen[cf.connectionType] {
+			seen[cf.connectionType] = true
+			types = append(types, cf.connectionType)
+		}
+	}
+	return types
+}
+
+// findMetaFilterKeys returns the distinct keys that meta filters require every matching item to have
+func findMetaFilterKeys(filters []filter.Filter) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, f := range filters {
+		if mf, ok := f.(*MetaFilter); ok && mf.comparison != filter.FilterNe && !seen[mf.key] {
+			seen[mf.key] = true
+			keys = append(keys, mf.key)
+		}
+	}
+	return keys
+}
+
+// findTextFilterWords returns the distinct words that text filters require every matching item to have
+func findTextFilterWords(filters []filter.Filter) []string {
+	words := []string{}
+	seen := map[string]bool{}
+	for _, f := range filters {
+		if tf, ok := f.(*TextFilter); ok {
+			for _, word := range tf.indexedWords() {
+				if !seen[word] {
+					seen[word] = true
+					words = append(words, word)
+				}
+			}
+		}
+	}
+	return words
+}
+
+// distinctWords returns the words in data once each, as stored in the text indexes
+func distinctWords(data string) []string {
+	tokenizer := &parser.Tokenizer{}
+	words := []string{}
+	seen := map[string]bool{}
+	for _, word := range tokenizer.Words(data) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// intersectRowIDs narrows found down to the given row ids. A nil found means nothing has been
+// narrowed yet, so everything in rowIDs is kept.
+func intersectRowIDs(found map[uint64]bool, rowIDs []uint64) map[uint64]bool {
+	narrowed := map[uint64]bool{}
+	for _, rowID := range rowIDs {
+		if found == nil || found[rowID] {
+			narrowed[rowID] = true
+		}
+	}
+	return narrowed
+}
+
+// unixOrZero stores optional times as 0 when they aren't set
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(unix int64) time.Time {
+	if unix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
+// MatchableItem wraps an Item so stores that don't have their own storage representation
+// can match it against filters
+type MatchableItem struct {
+	*Item
+}
+
+func (m MatchableItem) Datetime() int64 {
+	return m.Item.Time().Unix()
+}
+
+func (m MatchableItem) Due() int64 {
+	return unixOrZero(m.Item.Due())
+}
+
+func (m MatchableItem) CreatedAt() int64 {
+	return unixOrZero(m.Item.CreatedAt())
+}
+
+func (m MatchableItem) UpdatedAt() int64 {
+	return unixOrZero(m.Item.UpdatedAt())
+}
+
+func (m MatchableItem) CompletedAt() int64 {
+	return unixOrZero(m.Item.CompletedAt())
+}
+
+func (m MatchableItem) Priority() int64 {
+	return int64(m.Item.Priority())
+}
+
+func (m MatchableItem) Kind() int64 {
+	return int64(m.Item.Kind())
+}
+
+func (m MatchableItem) Meta() map[string]string {
+	return m.Item.Meta()
+}
+
+func (m MatchableItem) Tags() []string {
+	names := []string{}
+	for _, tag := range m.Item.Tags() {
+		names = append(names, tag.Name())
+	}
+	return names
+}
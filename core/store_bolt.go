@@ -2,14 +2,18 @@ package core
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/index"
+	"github.com/asdine/storm/q"
 	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+	bolt "go.etcd.io/bbolt"
 )
 
 type StormItem struct {
@@ -19,8 +23,60 @@ type StormItem struct {
 	PreviousID string
 	Data       string
 	Status     string
-	Datetime   int64 `storm:"index"`
-	Kind       int64 `storm:"index"`
+	Datetime   int64  `storm:"index"`
+	Kind       int64  `storm:"index"`
+	CustomKind string `storm:"index"` // empty for tasks and notes
+	Due        int64  `storm:"index"` // 0 if there is no due date
+	Priority   int64  `storm:"index"`
+	Recurrence string
+
+	// CreatedAt and UpdatedAt are 0 for items saved before they were recorded,
+	// CompletedAt is 0 until the item is done or skipped
+	CreatedAt   int64 `storm:"index"`
+	UpdatedAt   int64 `storm:"index"`
+	CompletedAt int64 `storm:"index"`
+
+	// Tags, Connections and Meta are nil for items saved before they were persisted
+	Tags        []string
+	Connections []string
+	Meta        map[string]string
+}
+
+// StormItemTag indexes items by tag, one row per tag on each item
+type StormItemTag struct {
+	RowID     uint64 `storm:"id,increment"`
+	ItemRowID uint64 `storm:"index"`
+	Name      string `storm:"index"`
+}
+
+// StormItemWord indexes items by the words in their data, one row per distinct word on each item
+type StormItemWord struct {
+	RowID     uint64 `storm:"id,increment"`
+	ItemRowID uint64 `storm:"index"`
+	Word      string `storm:"index"`
+}
+
+// StormItemMeta indexes items by their key:value fields, one row per field on each item
+type StormItemMeta struct {
+	RowID     uint64 `storm:"id,increment"`
+	ItemRowID uint64 `storm:"index"`
+	Key       string `storm:"index"`
+	Value     string
+}
+
+// StormItemConnection indexes items by their typed connections, one row per connection on each item
+type StormItemConnection struct {
+	RowID       uint64 `storm:"id,increment"`
+	ItemRowID   uint64 `storm:"index"`
+	Type        string `storm:"index"`
+	ConnectedID string
+}
+
+// StormTrashedItem keeps a trashed item, under the row id it had before it was trashed
+type StormTrashedItem struct {
+	RowID     uint64 `storm:"id"`
+	TrashedAt int64  `storm:"index"`
+	Item      *StormItem
 }
 
 // MatchableStormItem wraps a StormItem in order to implement the interface methods with
@@ -41,10 +97,65 @@ func (s MatchableStormItem) Datetime() int64 {
 	return s.StormItem.Datetime
 }
 
+func (s MatchableStormItem) Due() int64 {
+	return s.StormItem.Due
+}
+
+func (s MatchableStormItem) CreatedAt() int64 {
+	return s.StormItem.CreatedAt
+}
+
+func (s MatchableStormItem) UpdatedAt() int64 {
+	return s.StormItem.UpdatedAt
+}
+
+func (s MatchableStormItem) CompletedAt() int64 {
+	return s.StormItem.CompletedAt
+}
+
+func (s MatchableStormItem) Priority() int64 {
+	return s.StormItem.Priority
+}
+
 func (s MatchableStormItem) Kind() int64 {
 	return s.StormItem.Kind
 }
 
+func (s MatchableStormItem) CustomKind() string {
+	return s.StormItem.CustomKind
+}
+
+func (s MatchableStormItem) ID() string {
+	return s.StormItem.ID
+}
+
+func (s MatchableStormItem) Connections() []string {
+	if s.StormItem.Connections == nil {
+		tokenizer := &parser.Tokenizer{}
+		tokenResult, _ := tokenizer.Tokenize(s.StormItem.Data)
+		return tokenResult.Connections
+	}
+	return s.StormItem.Connections
+}
+
+func (s MatchableStormItem) Meta() map[string]string {
+	if s.StormItem.Meta == nil {
+		tokenizer := &parser.Tokenizer{}
+		tokenResult, _ := tokenizer.Tokenize(s.StormItem.Data)
+		return tokenResult.Metadata
+	}
+	return s.StormItem.Meta
+}
+
+func (s MatchableStormItem) Tags() []string {
+	if s.StormItem.Tags == nil {
+		tokenizer := &parser.Tokenizer{}
+		tokenResult, _ := tokenizer.Tokenize(s.StormItem.Data)
+		return tokenResult.Tags
+	}
+	return s.StormItem.Tags
+}
+
 type StormTag struct {
 	RowID     uint64 `storm:"id,increment"`
 	Name      string `storm:"index,unique"`
@@ -59,22 +170,181 @@ type StormGroup struct {
 	CreatedAt    int64 `storm:"index"` // timestamp
 }
 
+type StormRevision struct {
+	RowID  uint64 `storm:"id,increment"`
+	ItemID string `storm:"index"`
+	At     int64  // timestamp
+	Field  string
+	Old    string
+	New    string
+}
+
+type StormOperation struct {
+	RowID   uint64 `storm:"id,increment"`
+	Name    string
+	At      int64  // timestamp
+	Changes string // json
+}
+
+type StormInterval struct {
+	RowID   uint64 `storm:"id,increment"`
+	ItemID  string `storm:"index"`
+	Start   int64  // timestamp
+	End     int64  // timestamp, 0 while running
+	Running bool   `storm:"index"` // storm doesn't index zero values, so End can't be used to find these
+}
+
+// boltOpenTimeout bounds how long Open waits on another process holding the database
+const boltOpenTimeout = 5 * time.Second
+
+// boltHandle is shared between a BoltStore and any copies made by WithContext or Transaction,
+// so that they all see the database opened and closed
+type boltHandle struct {
+	db *storm.DB // only set between Open and Close
+}
+
 type BoltStore struct {
 	path string
 	ctx  context.Context
+	*boltHandle
+	tx storm.Node // only set inside a Transaction
 }
 
 func NewBoltStore(path string) (*BoltStore, error) {
-	store := &BoltStore{path: path}
-	db, err := storm.Open(store.path)
+	store := &BoltStore{path: path, boltHandle: &boltHandle{}}
+	err := store.Open()
+	if err != nil {
+		return nil, err
+	}
+	err = store.backfill()
 	if err != nil {
+		store.Close()
 		return nil, err
 	}
-	err = db.Close()
-	return store, err
+	return store, store.Close()
 }
 
-func (s *BoltStore) withOpenDB(f func(*storm.DB)) {
+// boltBackfillsBucket keeps how many of boltBackfills have been run on the database
+const boltBackfillsBucket = "Backfills"
+
+// boltBackfills fill in the indexes for items saved before them. They run in order, each once
+// and only on items already in the database, as every item saved after is indexed as it's saved.
+var boltBackfills = []func(s *BoltStore, stormItem *StormItem) error{
+	func(s *BoltStore, stormItem *StormItem) error {
+		stormItem.Tags = MatchableStormItem{StormItem: stormItem}.Tags()
+		return s.saveItemTags(stormItem)
+	},
+	func(s *BoltStore, stormItem *StormItem) error {
+		return s.saveItemWords(stormItem)
+	},
+	func(s *BoltStore, stormItem *StormItem) error {
+		stormItem.Meta = MatchableStormItem{StormItem: stormItem}.Meta()
+		return s.saveItemMeta(stormItem)
+	},
+	func(s *BoltStore, stormItem *StormItem) error {
+		stormItem.Connections = MatchableStormItem{StormItem: stormItem}.Connections()
+		return s.saveItemConnections(stormItem)
+	},
+}
+
+// backfill runs the backfills that haven't been run yet, each in its own transaction
+func (s *BoltStore) backfill() error {
+	done := 0
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = db.Get(boltBackfillsBucket, "done", &done)
+	})
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	for i := done; i < len(boltBackfills); i++ {
+		err = s.Transaction(func(tx Store) error {
+			boltTx := tx.(*BoltStore)
+			stormItems := []*StormItem{}
+			var err error
+			boltTx.withOpenDB(func(db storm.Node) {
+				err = db.All(&stormItems)
+			})
+			if err != nil {
+				return err
+			}
+			for _, stormItem := range stormItems {
+				err = boltBackfills[i](boltTx, stormItem)
+				if err != nil {
+					return err
+				}
+			}
+			boltTx.withOpenDB(func(db storm.Node) {
+				err = db.Set(boltBackfillsBucket, "done", i+1)
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to backfill bolt store %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Open holds the database open until Close is called. Without it, every operation opens
+// and closes the file itself, which is fine for a single call but slow for many.
+func (s *BoltStore) Open() error {
+	if s.db != nil {
+		return nil
+	}
+	db, err := storm.Open(s.path, storm.BoltOptions(0600, &bolt.Options{Timeout: boltOpenTimeout}))
+	if err == bolt.ErrTimeout {
+		return fmt.Errorf("database %s is in use by another process", s.path)
+	}
+	if err != nil {
+		return err
+	}
+	s.db = db
+	return nil
+}
+
+func (s *BoltStore) Close() error {
+	if s.db == nil || s.tx != nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// Transaction runs f against a store where every operation happens in a single bolt
+// transaction, committed only if f returns nil. Nested transactions join the outer one.
+func (s *BoltStore) Transaction(f func(tx Store) error) error {
+	if s.tx != nil {
+		return f(s)
+	}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		var tx storm.Node
+		tx, err = db.Begin(true)
+		if err != nil {
+			return
+		}
+		err = f(&BoltStore{path: s.path, ctx: s.ctx, boltHandle: s.boltHandle, tx: tx})
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	})
+	return err
+}
+
+func (s *BoltStore) withOpenDB(f func(db storm.Node)) {
+	if s.tx != nil {
+		f(s.tx)
+		return
+	}
+	if s.db != nil {
+		f(s.db)
+		return
+	}
 	db, err := storm.Open(s.path)
 	if err != nil {
 		panic(err)
@@ -86,15 +356,15 @@ func (s *BoltStore) withOpenDB(f func(*storm.DB)) {
 func (s *BoltStore) FindAll(id string) ([]*Item, error) {
 	stormItems := []*StormItem{}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.Prefix("ID", id, &stormItems)
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, boltError(err)
 	}
 	if len(stormItems) < 1 {
-		return nil, errors.New("not found")
+		return nil, ErrNotFound
 	}
 
 	items := []*Item{}
@@ -114,10 +384,17 @@ func (s *BoltStore) Delete(item *Item) error {
 		return nil
 	}
 	stormItem.RowID = i
-	s.withOpenDB(func(db *storm.DB) {
-		err = db.DeleteStruct(stormItem)
+	return s.Transaction(func(tx Store) error {
+		boltTx := tx.(*BoltStore)
+		var err error
+		boltTx.withOpenDB(func(db storm.Node) {
+			err = db.DeleteStruct(stormItem)
+		})
+		if err != nil {
+			return boltError(err)
+		}
+		return boltTx.deleteItemIndexes(stormItem.RowID)
 	})
-	return err
 }
 
 func (s *BoltStore) Save(item *Item) error {
@@ -128,49 +405,335 @@ func (s *BoltStore) Save(item *Item) error {
 			return err
 		}
 		stormItem.RowID = i
-		s.withOpenDB(func(db *storm.DB) {
-			err = db.Update(stormItem)
+	}
+
+	err := s.Transaction(func(tx Store) error {
+		boltTx := tx.(*BoltStore)
+		var err error
+		boltTx.withOpenDB(func(db storm.Node) {
+			if stormItem.RowID != 0 {
+				// Update skips zero values, so check it exists and then overwrite it all
+				err = db.One("RowID", stormItem.RowID, &StormItem{})
+				if err != nil {
+					return
+				}
+			}
+			err = db.Save(stormItem)
 		})
+		if err != nil {
+			return boltError(err)
+		}
+		return boltTx.saveItemIndexes(stormItem)
+	})
+	if err != nil {
+		return err
+	}
+	item.internalID = fmt.Sprintf("%d", stormItem.RowID)
+	return nil
+}
+
+// saveItemIndexes replaces the rows indexing an item by its tags, meta, connections and words
+func (s *BoltStore) saveItemIndexes(stormItem *StormItem) error {
+	err := s.saveItemTags(stormItem)
+	if err != nil {
+		return err
+	}
+	err = s.saveItemMeta(stormItem)
+	if err != nil {
+		return err
+	}
+	err = s.saveItemConnections(stormItem)
+	if err != nil {
+		return err
+	}
+	return s.saveItemWords(stormItem)
+}
+
+func (s *BoltStore) deleteItemIndexes(itemRowID uint64) error {
+	err := s.deleteItemTags(itemRowID)
+	if err != nil {
+		return err
+	}
+	err = s.deleteItemMeta(itemRowID)
+	if err != nil {
+		return err
+	}
+	err = s.deleteItemConnections(itemRowID)
+	if err != nil {
+		return err
+	}
+	return s.deleteItemWords(itemRowID)
+}
+
+func (s *BoltStore) saveItemTags(stormItem *StormItem) error {
+	err := s.deleteItemTags(stormItem.RowID)
+	if err != nil {
+		return err
+	}
+	s.withOpenDB(func(db storm.Node) {
+		for _, name := range stormItem.Tags {
+			err = db.Save(&StormItemTag{ItemRowID: stormItem.RowID, Name: name})
+			if err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+func (s *BoltStore) deleteItemTags(itemRowID uint64) error {
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		itemTags := []*StormItemTag{}
+		err = db.Find("ItemRowID", itemRowID, &itemTags)
+		for _, itemTag := range itemTags {
+			err = db.DeleteStruct(itemTag)
+			if err != nil {
+				return
+			}
+		}
+	})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *BoltStore) saveItemMeta(stormItem *StormItem) error {
+	err := s.deleteItemMeta(stormItem.RowID)
+	if err != nil {
 		return err
 	}
+	s.withOpenDB(func(db storm.Node) {
+		for key, value := range stormItem.Meta {
+			err = db.Save(&StormItemMeta{ItemRowID: stormItem.RowID, Key: key, Value: value})
+			if err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+func (s *BoltStore) deleteItemMeta(itemRowID uint64) error {
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
-		err = db.Save(stormItem)
+	s.withOpenDB(func(db storm.Node) {
+		itemMeta := []*StormItemMeta{}
+		err = db.Find("ItemRowID", itemRowID, &itemMeta)
+		for _, field := range itemMeta {
+			err = db.DeleteStruct(field)
+			if err != nil {
+				return
+			}
+		}
 	})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *BoltStore) saveItemConnections(stormItem *StormItem) error {
+	err := s.deleteItemConnections(stormItem.RowID)
 	if err != nil {
 		return err
 	}
-	item.internalID = fmt.Sprintf("%d", stormItem.RowID)
-	return nil
+	s.withOpenDB(func(db storm.Node) {
+		for _, connection := range stormItem.Connections {
+			connectionType, connectedID := parser.SplitConnection(connection)
+			err = db.Save(&StormItemConnection{ItemRowID: stormItem.RowID, Type: connectionType, ConnectedID: connectedID})
+			if err != nil {
+				return
+			}
+		}
+	})
+	return err
 }
 
-func (s *BoltStore) findFirstDateFilter(filters []filter.Filter) (*DateFilter, []filter.Filter) {
-	var rest []filter.Filter
-	for i, f := range filters {
-		switch df := f.(type) {
-		case *DateFilter:
-			rest = append(filters[:i], filters[i+1:]...)
-			return df, rest
+func (s *BoltStore) deleteItemConnections(itemRowID uint64) error {
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		itemConnections := []*StormItemConnection{}
+		err = db.Find("ItemRowID", itemRowID, &itemConnections)
+		for _, itemConnection := range itemConnections {
+			err = db.DeleteStruct(itemConnection)
+			if err != nil {
+				return
+			}
 		}
+	})
+	if err == storm.ErrNotFound {
+		return nil
 	}
-	return nil, filters
+	return err
+}
+
+func (s *BoltStore) saveItemWords(stormItem *StormItem) error {
+	err := s.deleteItemWords(stormItem.RowID)
+	if err != nil {
+		return err
+	}
+	s.withOpenDB(func(db storm.Node) {
+		for _, word := range distinctWords(stormItem.Data) {
+			err = db.Save(&StormItemWord{ItemRowID: stormItem.RowID, Word: word})
+			if err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+func (s *BoltStore) deleteItemWords(itemRowID uint64) error {
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		itemWords := []*StormItemWord{}
+		err = db.Find("ItemRowID", itemRowID, &itemWords)
+		for _, itemWord := range itemWords {
+			err = db.DeleteStruct(itemWord)
+			if err != nil {
+				return
+			}
+		}
+	})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// indexedItemRowIDs uses the tag, meta, word and connection indexes to find the items that have
+// every one of the given tags, metadata keys, words and connection types
+func (s *BoltStore) indexedItemRowIDs(db storm.Node, tagFilters []*TagFilter, metaKeys []string, words []string, connectionTypes []string) (map[uint64]bool, error) {
+	var found map[uint64]bool
+	for _, tagFilter := range tagFilters {
+		itemTags := []*StormItemTag{}
+		err := db.Find("Name", tagFilter.tagName, &itemTags)
+		if err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+
+		rowIDs := []uint64{}
+		for _, itemTag := range itemTags {
+			rowIDs = append(rowIDs, itemTag.ItemRowID)
+		}
+		found = intersectRowIDs(found, rowIDs)
+	}
+	for _, key := range metaKeys {
+		itemMeta := []*StormItemMeta{}
+		err := db.Find("Key", key, &itemMeta)
+		if err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+
+		rowIDs := []uint64{}
+		for _, field := range itemMeta {
+			rowIDs = append(rowIDs, field.ItemRowID)
+		}
+		found = intersectRowIDs(found, rowIDs)
+	}
+	for _, word := range words {
+		itemWords := []*StormItemWord{}
+		err := db.Find("Word", word, &itemWords)
+		if err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+
+		rowIDs := []uint64{}
+		for _, itemWord := range itemWords {
+			rowIDs = append(rowIDs, itemWord.ItemRowID)
+		}
+		found = intersectRowIDs(found, rowIDs)
+	}
+	for _, connectionType := range connectionTypes {
+		itemConnections := []*StormItemConnection{}
+		err := db.Find("Type", connectionType, &itemConnections)
+		if err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+
+		rowIDs := []uint64{}
+		for _, itemConnection := range itemConnections {
+			rowIDs = append(rowIDs, itemConnection.ItemRowID)
+		}
+		found = intersectRowIDs(found, rowIDs)
+	}
+	return found, nil
 }
 
 func (s *BoltStore) ListFilters(filters []filter.Filter) ([]*Item, error) {
 	stormItems := []*StormItem{}
 	outputItems := []*Item{}
 
-	firstDateFilter, rest := s.findFirstDateFilter(filters)
+	options, filters := findListOptions(filters)
+	firstDateFilter, rest := findFirstDateFilter(filters)
+	tagFilters := findTagEqFilters(rest)
+	metaKeys := findMetaFilterKeys(rest)
+	words := findTextFilterWords(rest)
+	connectionTypes := findConnectionTypes(rest)
+	pageByIndex := len(rest) == 0 && options.byTime() && options.paged()
 	var err error
 
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
+		var indexed map[uint64]bool
+		if len(tagFilters) > 0 || len(metaKeys) > 0 || len(words) > 0 || len(connectionTypes) > 0 {
+			// if we have tag, meta, text or connection filters, the indexes tell us every item that could match
+			indexed, err = s.indexedItemRowIDs(db, tagFilters, metaKeys, words, connectionTypes)
+			if err != nil {
+				return
+			}
+		}
+
+		if pageByIndex {
+			// nothing else to match, so the time index can sort and page for us
+			stormOptions := []func(*index.Options){storm.Skip(options.offset)}
+			if options.limit != 0 {
+				stormOptions = append(stormOptions, storm.Limit(options.limit))
+			}
+			if options.descendingTime() {
+				stormOptions = append(stormOptions, storm.Reverse())
+			}
+			if firstDateFilter != nil {
+				err = db.Range("Datetime", firstDateFilter.timespan.Start.Unix(), firstDateFilter.timespan.End.Unix(), &stormItems, stormOptions...)
+			} else {
+				err = db.AllByIndex("Datetime", &stormItems, stormOptions...)
+			}
+			return
+		}
+
 		if firstDateFilter != nil {
 			// if we have a date filter, use it as a range to limit where we search over
 			err = db.Range("Datetime", firstDateFilter.timespan.Start.Unix(), firstDateFilter.timespan.End.Unix(), &stormItems)
+		} else if indexed != nil {
+			// else, load only the indexed items
+			rowIDs := []uint64{}
+			for rowID := range indexed {
+				rowIDs = append(rowIDs, rowID)
+			}
+			sort.Slice(rowIDs, func(i, j int) bool { return rowIDs[i] < rowIDs[j] })
+			for _, rowID := range rowIDs {
+				stormItem := &StormItem{}
+				err = db.One("RowID", rowID, stormItem)
+				if err != nil {
+					return
+				}
+				stormItems = append(stormItems, stormItem)
+			}
+			return
 		} else {
 			// else, get all
 			err = db.All(&stormItems)
 		}
+
+		if indexed != nil {
+			inRange := stormItems
+			stormItems = []*StormItem{}
+			for _, stormItem := range inRange {
+				if indexed[stormItem.RowID] {
+					stormItems = append(stormItems, stormItem)
+				}
+			}
+		}
 	})
 
 	if err != nil {
@@ -198,30 +761,156 @@ func (s *BoltStore) ListFilters(filters []filter.Filter) ([]*Item, error) {
 			outputItems = append(outputItems, parsed)
 		}
 	}
-	sort.Slice(outputItems, func(i, j int) bool {
+	if pageByIndex {
+		return outputItems, nil
+	}
+	sort.SliceStable(outputItems, func(i, j int) bool {
 		return outputItems[i].Time().Before(outputItems[j].Time())
 	})
+	options.sort(outputItems)
+	return options.page(outputItems), nil
+}
+
+func (s *BoltStore) Trash(item *Item) error {
+	rowID, err := strconv.ParseUint(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	trashedAt := time.Now()
+	err = s.Transaction(func(tx Store) error {
+		boltTx := tx.(*BoltStore)
+		err := boltTx.Delete(item)
+		if err != nil {
+			return err
+		}
+		stormItem := boltTx.itemToNewStorm(item)
+		stormItem.RowID = rowID
+		boltTx.withOpenDB(func(db storm.Node) {
+			err = db.Save(&StormTrashedItem{RowID: rowID, TrashedAt: trashedAt.Unix(), Item: stormItem})
+		})
+		return boltError(err)
+	})
+	if err != nil {
+		return err
+	}
+	item.trashedAt = time.Unix(trashedAt.Unix(), 0)
+	return nil
+}
+
+func (s *BoltStore) ListTrash() ([]*Item, error) {
+	trashed := []*StormTrashedItem{}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = db.AllByIndex("TrashedAt", &trashed)
+	})
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	items := []*Item{}
+	for _, trashedItem := range trashed {
+		item, err := s.stormToItem(trashedItem.Item)
+		if err != nil {
+			return nil, err
+		}
+		item.trashedAt = time.Unix(trashedItem.TrashedAt, 0)
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].TrashedAt().Before(items[j].TrashedAt())
+	})
+	return items, nil
+}
+
+func (s *BoltStore) FindAllTrash(id string) ([]*Item, error) {
+	trashed, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	items := []*Item{}
+	for _, item := range trashed {
+		if strings.HasPrefix(item.ID(), id) {
+			items = append(items, item)
+		}
+	}
+	if len(items) < 1 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID() < items[j].ID()
+	})
+	return items, nil
+}
+
+func (s *BoltStore) Restore(item *Item) error {
+	rowID, err := strconv.ParseUint(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	err = s.Transaction(func(tx Store) error {
+		boltTx := tx.(*BoltStore)
+		trashed := &StormTrashedItem{}
+		var err error
+		boltTx.withOpenDB(func(db storm.Node) {
+			err = db.One("RowID", rowID, trashed)
+			if err != nil {
+				return
+			}
+			// saving with the old row id puts the item back where it was
+			trashed.Item.RowID = rowID
+			err = db.Save(trashed.Item)
+			if err != nil {
+				return
+			}
+			err = db.DeleteStruct(trashed)
+		})
+		if err != nil {
+			return boltError(err)
+		}
+		return boltTx.saveItemIndexes(trashed.Item)
+	})
+	if err != nil {
+		return err
+	}
+	item.trashedAt = time.Time{}
+	return nil
+}
 
-	return outputItems, nil
+func (s *BoltStore) DeleteTrash(item *Item) error {
+	rowID, err := strconv.ParseUint(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	s.withOpenDB(func(db storm.Node) {
+		err = db.DeleteStruct(&StormTrashedItem{RowID: rowID})
+	})
+	return boltError(err)
 }
 
 func (s *BoltStore) FindTag(name string) (*Tag, error) {
 	stormTag := &StormTag{}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.One("Name", name, stormTag)
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, boltError(err)
 	}
 	return s.stormToTag(stormTag)
 }
 
 func (s *BoltStore) SaveTag(tag *Tag) error {
+	// look before saving, as a failed save part way through a transaction can
+	// leave the other tag indexes behind
+	found, err := s.FindTag(tag.Name())
+	if err == nil {
+		tag.internalID = found.internalID
+		return nil
+	}
+
 	stormTag := s.tagToStorm(tag)
-	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.Save(stormTag)
 	})
 	if err != nil {
@@ -239,7 +928,7 @@ func (s *BoltStore) SaveTag(tag *Tag) error {
 func (s *BoltStore) ListTags() ([]*Tag, error) {
 	stormTags := []*StormTag{}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		query := db.Select()
 		query.OrderBy("CreatedAt")
 		err = query.Find(&stormTags)
@@ -271,17 +960,17 @@ func (s *BoltStore) SaveGroup(group *Group) error {
 			return err
 		}
 		stormGroup.RowID = i
-		s.withOpenDB(func(db *storm.DB) {
+		s.withOpenDB(func(db storm.Node) {
 			err = db.Update(stormGroup)
 		})
-		return err
+		return boltError(err)
 	}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.Save(stormGroup)
 	})
 	if err != nil {
-		return err
+		return boltError(err)
 	}
 	group.internalID = fmt.Sprintf("%d", stormGroup.RowID)
 	return nil
@@ -294,16 +983,16 @@ func (s *BoltStore) DeleteGroup(group *Group) error {
 		return nil
 	}
 	stormGroup.RowID = i
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.DeleteStruct(stormGroup)
 	})
-	return err
+	return boltError(err)
 }
 
 func (s *BoltStore) ListGroups() ([]*Group, error) {
 	stormGroups := []*StormGroup{}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		query := db.Select()
 		query.OrderBy("CreatedAt")
 		err = query.Find(&stormGroups)
@@ -330,40 +1019,258 @@ func (s *BoltStore) ListGroups() ([]*Group, error) {
 func (s *BoltStore) FindGroupByName(name string) (*Group, error) {
 	stormGroup := &StormGroup{}
 	var err error
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		err = db.One("Name", name, stormGroup)
 	})
 	if err != nil {
-		return nil, err
+		return nil, boltError(err)
 	}
 	return s.stormToGroup(stormGroup)
 }
 
+func (s *BoltStore) SaveRevision(revision *Revision) error {
+	stormRevision := &StormRevision{
+		ItemID: revision.ItemID,
+		At:     revision.At.Unix(),
+		Field:  revision.Field,
+		Old:    revision.Old,
+		New:    revision.New,
+	}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = db.Save(stormRevision)
+	})
+	if err != nil {
+		return err
+	}
+	revision.internalID = fmt.Sprintf("%d", stormRevision.RowID)
+	return nil
+}
+
+func (s *BoltStore) ListRevisions(itemID string) ([]*Revision, error) {
+	stormRevisions := []*StormRevision{}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = db.Find("ItemID", itemID, &stormRevisions)
+	})
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	revisions := []*Revision{}
+	for _, stormRevision := range stormRevisions {
+		revisions = append(revisions, &Revision{
+			internalID: fmt.Sprintf("%d", stormRevision.RowID),
+			ItemID:     stormRevision.ItemID,
+			At:         time.Unix(stormRevision.At, 0),
+			Field:      stormRevision.Field,
+			Old:        stormRevision.Old,
+			New:        stormRevision.New,
+		})
+	}
+	// row ids increase as revisions are saved, so they break ties within the same second
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].At.Before(revisions[j].At)
+	})
+	return revisions, nil
+}
+
+func (s *BoltStore) SaveOperation(operation *Operation) error {
+	changes, err := encodeChanges(operation.Changes)
+	if err != nil {
+		return err
+	}
+	stormOperation := &StormOperation{Name: operation.Name, At: operation.At.Unix(), Changes: changes}
+	s.withOpenDB(func(db storm.Node) {
+		err = db.Save(stormOperation)
+	})
+	if err != nil {
+		return err
+	}
+	operation.internalID = fmt.Sprintf("%d", stormOperation.RowID)
+	return nil
+}
+
+func (s *BoltStore) ListOperations() ([]*Operation, error) {
+	stormOperations := []*StormOperation{}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = db.All(&stormOperations, storm.Reverse())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []*Operation{}
+	for _, stormOperation := range stormOperations {
+		changes, err := decodeChanges(stormOperation.Changes)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, &Operation{
+			internalID: fmt.Sprintf("%d", stormOperation.RowID),
+			Name:       stormOperation.Name,
+			At:         time.Unix(stormOperation.At, 0),
+			Changes:    changes,
+		})
+	}
+	return operations, nil
+}
+
+func (s *BoltStore) DeleteOperation(operation *Operation) error {
+	rowID, err := strconv.ParseUint(operation.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	s.withOpenDB(func(db storm.Node) {
+		err = db.DeleteStruct(&StormOperation{RowID: rowID})
+	})
+	return boltError(err)
+}
+
+func (s *BoltStore) SaveInterval(interval *Interval) error {
+	stormInterval := &StormInterval{
+		ItemID:  interval.ItemID,
+		Start:   interval.Start.Unix(),
+		End:     unixOrZero(interval.End),
+		Running: interval.Running(),
+	}
+	var err error
+	if interval.internalID != "" {
+		stormInterval.RowID, err = strconv.ParseUint(interval.internalID, 10, 64)
+		if err != nil {
+			return ErrNotFound
+		}
+		s.withOpenDB(func(db storm.Node) {
+			err = db.One("RowID", stormInterval.RowID, &StormInterval{})
+			if err == nil {
+				// Save rather than Update, so that Running can be set back to false
+				err = db.Save(stormInterval)
+			}
+		})
+		return boltError(err)
+	}
+	s.withOpenDB(func(db storm.Node) {
+		err = db.Save(stormInterval)
+	})
+	if err != nil {
+		return err
+	}
+	interval.internalID = fmt.Sprintf("%d", stormInterval.RowID)
+	return nil
+}
+
+func (s *BoltStore) ListIntervals(itemID string) ([]*Interval, error) {
+	return s.findIntervals(func(db storm.Node, to *[]*StormInterval) error {
+		return db.Find("ItemID", itemID, to)
+	})
+}
+
+func (s *BoltStore) ListRunningIntervals() ([]*Interval, error) {
+	return s.findIntervals(func(db storm.Node, to *[]*StormInterval) error {
+		return db.Find("Running", true, to)
+	})
+}
+
+func (s *BoltStore) ListIntervalsBetween(start, end time.Time) ([]*Interval, error) {
+	return s.findIntervals(func(db storm.Node, to *[]*StormInterval) error {
+		return db.Select(q.Lt("Start", end.Unix()), q.Or(q.Eq("Running", true), q.Gt("End", start.Unix()))).Find(to)
+	})
+}
+
+func (s *BoltStore) findIntervals(find func(db storm.Node, to *[]*StormInterval) error) ([]*Interval, error) {
+	stormIntervals := []*StormInterval{}
+	var err error
+	s.withOpenDB(func(db storm.Node) {
+		err = find(db, &stormIntervals)
+	})
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	intervals := []*Interval{}
+	for _, stormInterval := range stormIntervals {
+		intervals = append(intervals, &Interval{
+			internalID: fmt.Sprintf("%d", stormInterval.RowID),
+			ItemID:     stormInterval.ItemID,
+			Start:      time.Unix(stormInterval.Start, 0),
+			End:        timeOrZero(stormInterval.End),
+		})
+	}
+	sort.SliceStable(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+	return intervals, nil
+}
+
+func (s *BoltStore) DeleteInterval(interval *Interval) error {
+	rowID, err := strconv.ParseUint(interval.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	s.withOpenDB(func(db storm.Node) {
+		err = db.DeleteStruct(&StormInterval{RowID: rowID})
+	})
+	return boltError(err)
+}
+
 func (s *BoltStore) WithContext(ctx context.Context) Store {
-	return &BoltStore{ctx: ctx, path: s.path}
+	return &BoltStore{ctx: ctx, path: s.path, boltHandle: s.boltHandle, tx: s.tx}
 }
 
 func (s *BoltStore) DropBucket(bucket string) {
-	s.withOpenDB(func(db *storm.DB) {
+	s.withOpenDB(func(db storm.Node) {
 		db.Drop(bucket)
 	})
 }
 
+// DropBuckets empties the store by dropping every bucket in it
+func (s *BoltStore) DropBuckets() {
+	s.withOpenDB(func(db storm.Node) {
+		for _, bucket := range db.PrefixScan("") {
+			db.Drop(bucket.Bucket()[0])
+		}
+	})
+}
+
+// boltError translates storm errors into the store errors shared by all backends
+func boltError(err error) error {
+	switch err {
+	case storm.ErrNotFound:
+		return ErrNotFound
+	case storm.ErrAlreadyExists:
+		return ErrAlreadyExists
+	}
+	return err
+}
+
 func (s *BoltStore) itemToNewStorm(input *Item) *StormItem {
+	tokenizer := &parser.Tokenizer{}
+	tokenResult, _ := tokenizer.Tokenize(input.Data())
 	return &StormItem{
-		ID:         input.ID(),
-		PreviousID: input.PreviousID(),
-		NextID:     input.NextID(),
-		Data:       input.Data(),
-		Status:     input.Status(),
-		Datetime:   input.Time().Unix(),
-		Kind:       int64(input.Kind()),
+		ID:          input.ID(),
+		PreviousID:  input.PreviousID(),
+		NextID:      input.NextID(),
+		Data:        input.Data(),
+		Status:      input.Status(),
+		Datetime:    input.Time().Unix(),
+		Kind:        int64(input.Kind()),
+		CustomKind:  input.CustomKind(),
+		Due:         unixOrZero(input.Due()),
+		Priority:    int64(input.Priority()),
+		Recurrence:  input.Recurrence(),
+		CreatedAt:   unixOrZero(input.CreatedAt()),
+		UpdatedAt:   unixOrZero(input.UpdatedAt()),
+		CompletedAt: unixOrZero(input.CompletedAt()),
+		Tags:        tokenResult.Tags,
+		Connections: tokenResult.Connections,
+		Meta:        tokenResult.Metadata,
 	}
 }
 
 func (s *BoltStore) stormToItem(input *StormItem) (*Item, error) {
 	parsedTime := time.Unix(input.Datetime, 0)
-	return &Item{
+	item := &Item{
 		internalID: fmt.Sprintf("%d", input.RowID),
 		id:         input.ID,
 		previousID: input.PreviousID,
@@ -372,7 +1279,25 @@ func (s *BoltStore) stormToItem(input *StormItem) (*Item, error) {
 		status:     input.Status,
 		datetime:   parsedTime,
 		kind:       Kind(input.Kind),
-	}, nil
+		customKind: input.CustomKind,
+		due:        timeOrZero(input.Due),
+		priority:   Priority(input.Priority),
+		recurrence: input.Recurrence,
+
+		createdAt:   timeOrZero(input.CreatedAt),
+		updatedAt:   timeOrZero(input.UpdatedAt),
+		completedAt: timeOrZero(input.CompletedAt),
+	}
+	if input.Tags != nil && input.Connections != nil && input.Meta != nil {
+		// persisted, so no need to tokenize the data again
+		item.tags = []*Tag{}
+		for _, name := range input.Tags {
+			item.tags = append(item.tags, NewTag(name))
+		}
+		item.connections = input.Connections
+		item.meta = input.Meta
+	}
+	return item, nil
 }
 
 func (s *BoltStore) tagToStorm(input *Tag) *StormTag {
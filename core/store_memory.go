@@ -0,0 +1,736 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+)
+
+type memoryState struct {
+	lastItemRowID      uint64
+	lastTagRowID       uint64
+	lastGroupRowID     uint64
+	lastRevisionRowID  uint64
+	lastOperationRowID uint64
+	lastIntervalRowID  uint64
+
+	items           map[uint64]*Item
+	timeline        []uint64                   // item row ids, sorted by time
+	tagIndex        map[string]map[uint64]bool // tag name to item row ids
+	metaIndex       map[string]map[uint64]bool // metadata key to item row ids
+	wordIndex       map[string]map[uint64]bool // word to item row ids
+	connectionIndex map[string]map[uint64]bool // connection type to item row ids
+	trash           map[uint64]*Item           // trashed items, by the row id they had
+	tags            map[string]*Tag
+	groups          map[uint64]*Group
+	revisions       []*Revision  // in the order they were saved
+	operations      []*Operation // in the order they were saved
+	intervals       map[uint64]*Interval
+}
+
+// copy is enough for a snapshot, as stored values are replaced on save, never modified
+func (ms memoryState) copy() memoryState {
+	copied := ms
+	copied.items = map[uint64]*Item{}
+	for k, v := range ms.items {
+		copied.items[k] = v
+	}
+	copied.timeline = append([]uint64{}, ms.timeline...)
+	copied.tagIndex = copyIndex(ms.tagIndex)
+	copied.metaIndex = copyIndex(ms.metaIndex)
+	copied.wordIndex = copyIndex(ms.wordIndex)
+	copied.connectionIndex = copyIndex(ms.connectionIndex)
+	copied.trash = map[uint64]*Item{}
+	for k, v := range ms.trash {
+		copied.trash[k] = v
+	}
+	copied.tags = map[string]*Tag{}
+	for k, v := range ms.tags {
+		copied.tags[k] = v
+	}
+	copied.revisions = append([]*Revision{}, ms.revisions...)
+	copied.operations = append([]*Operation{}, ms.operations...)
+	copied.groups = map[uint64]*Group{}
+	for k, v := range ms.groups {
+		copied.groups[k] = v
+	}
+	copied.intervals = map[uint64]*Interval{}
+	for k, v := range ms.intervals {
+		copied.intervals[k] = v
+	}
+	return copied
+}
+
+func copyIndex(index map[string]map[uint64]bool) map[string]map[uint64]bool {
+	copied := map[string]map[uint64]bool{}
+	for key, rowIDs := range index {
+		copied[key] = map[uint64]bool{}
+		for rowID := range rowIDs {
+			copied[key][rowID] = true
+		}
+	}
+	return copied
+}
+
+// memoryData is shared between a MemoryStore and any copies made by WithContext
+type memoryData struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // held for the length of a transaction
+	memoryState
+}
+
+// MemoryStore keeps everything in memory, and loses it all when the process exits.
+type MemoryStore struct {
+	*memoryData
+	ctx           context.Context
+	inTransaction bool
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		memoryData: &memoryData{
+			memoryState: memoryState{
+				items:           map[uint64]*Item{},
+				tagIndex:        map[string]map[uint64]bool{},
+				metaIndex:       map[string]map[uint64]bool{},
+				wordIndex:       map[string]map[uint64]bool{},
+				connectionIndex: map[string]map[uint64]bool{},
+				trash:           map[uint64]*Item{},
+				tags:            map[string]*Tag{},
+				groups:          map[uint64]*Group{},
+				intervals:       map[uint64]*Interval{},
+			},
+		},
+	}
+}
+
+func (s *MemoryStore) Open() error {
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Transaction runs f, putting everything back as it was if f returns an error.
+// Transactions are serialised with each other, but not with writes made outside of one.
+func (s *MemoryStore) Transaction(f func(tx Store) error) error {
+	if s.inTransaction {
+		return f(s)
+	}
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.memoryState.copy()
+	s.mu.Unlock()
+
+	err := f(&MemoryStore{memoryData: s.memoryData, ctx: s.ctx, inTransaction: true})
+	if err != nil {
+		s.mu.Lock()
+		s.memoryState = snapshot
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *MemoryStore) FindAll(id string) ([]*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []*Item{}
+	for _, item := range s.items {
+		if strings.HasPrefix(item.ID(), id) {
+			items = append(items, copyItem(item))
+		}
+	}
+	if len(items) < 1 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID() < items[j].ID()
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) Delete(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID, err := strconv.ParseUint(item.internalID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if _, ok := s.items[rowID]; !ok {
+		return ErrNotFound
+	}
+	s.removeFromIndexes(rowID)
+	delete(s.items, rowID)
+	s.removeFromTimeline(rowID)
+	return nil
+}
+
+func (s *MemoryStore) Save(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rowID uint64
+	if item.internalID != "" {
+		var err error
+		rowID, err = strconv.ParseUint(item.internalID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.items[rowID]; !ok {
+			return ErrNotFound
+		}
+	}
+
+	for existingRowID, existing := range s.items {
+		if existing.ID() == item.ID() && existingRowID != rowID {
+			return ErrAlreadyExists
+		}
+	}
+
+	if rowID == 0 {
+		s.lastItemRowID++
+		rowID = s.lastItemRowID
+	} else {
+		s.removeFromTimeline(rowID)
+		s.removeFromIndexes(rowID)
+	}
+	item.internalID = fmt.Sprintf("%d", rowID)
+	s.items[rowID] = copyItem(item)
+	s.insertIntoTimeline(rowID)
+	s.addToIndexes(rowID)
+	return nil
+}
+
+func (s *MemoryStore) ListFilters(filters []filter.Filter) ([]*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outputItems := []*Item{}
+	options, filters := findListOptions(filters)
+	firstDateFilter, rest := findFirstDateFilter(filters)
+	indexed := s.indexedItemRowIDs(findTagEqFilters(rest), findMetaFilterKeys(rest), findTextFilterWords(rest), findConnectionTypes(rest))
+
+	candidates := s.timeline
+	if firstDateFilter != nil {
+		// if we have a date filter, use it as a range to limit where we search over
+		start := sort.Search(len(s.timeline), func(i int) bool {
+			return s.items[s.timeline[i]].Time().Unix() >= firstDateFilter.timespan.Start.Unix()
+		})
+		end := sort.Search(len(s.timeline), func(i int) bool {
+			return s.items[s.timeline[i]].Time().Unix() > firstDateFilter.timespan.End.Unix()
+		})
+		if start >= end {
+			return outputItems, nil
+		}
+		candidates = s.timeline[start:end]
+	}
+
+	// when sorting by time, the timeline is already in order, so we can stop at the limit
+	byTime := options.byTime()
+	descending := byTime && options.descendingTime()
+	for i := range candidates {
+		if byTime && options.limit != 0 && len(outputItems) == options.offset+options.limit {
+			break
+		}
+		rowID := candidates[i]
+		if descending {
+			rowID = candidates[len(candidates)-1-i]
+		}
+		if indexed != nil && !indexed[rowID] {
+			continue
+		}
+		item := s.items[rowID]
+		match := true
+		for _, filter := range rest {
+			ok, err := filter.Match(MatchableItem{Item: item})
+			if !ok || err != nil {
+				match = false
+				break
+			}
+		}
+		if match {
+			outputItems = append(outputItems, copyItem(item))
+		}
+	}
+	if !byTime {
+		options.sort(outputItems)
+	}
+	return options.page(outputItems), nil
+}
+
+// insertIntoTimeline keeps the timeline ordered by time, with items at the same
+// second kept in the order they were first saved
+func (s *MemoryStore) insertIntoTimeline(rowID uint64) {
+	at := s.items[rowID].Time().Unix()
+	i := sort.Search(len(s.timeline), func(i int) bool {
+		other := s.items[s.timeline[i]].Time().Unix()
+		return other > at || (other == at && s.timeline[i] > rowID)
+	})
+	s.timeline = append(s.timeline, 0)
+	copy(s.timeline[i+1:], s.timeline[i:])
+	s.timeline[i] = rowID
+}
+
+func (s *MemoryStore) removeFromTimeline(rowID uint64) {
+	for i, existing := range s.timeline {
+		if existing == rowID {
+			s.timeline = append(s.timeline[:i], s.timeline[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *MemoryStore) addToIndexes(rowID uint64) {
+	for _, tag := range s.items[rowID].Tags() {
+		if s.tagIndex[tag.Name()] == nil {
+			s.tagIndex[tag.Name()] = map[uint64]bool{}
+		}
+		s.tagIndex[tag.Name()][rowID] = true
+	}
+	for key := range s.items[rowID].Meta() {
+		if s.metaIndex[key] == nil {
+			s.metaIndex[key] = map[uint64]bool{}
+		}
+		s.metaIndex[key][rowID] = true
+	}
+	for _, word := range distinctWords(s.items[rowID].Data()) {
+		if s.wordIndex[word] == nil {
+			s.wordIndex[word] = map[uint64]bool{}
+		}
+		s.wordIndex[word][rowID] = true
+	}
+	for _, connection := range s.items[rowID].Connections() {
+		connectionType, _ := parser.SplitConnection(connection)
+		if s.connectionIndex[connectionType] == nil {
+			s.connectionIndex[connectionType] = map[uint64]bool{}
+		}
+		s.connectionIndex[connectionType][rowID] = true
+	}
+}
+
+func (s *MemoryStore) removeFromIndexes(rowID uint64) {
+	for _, tag := range s.items[rowID].Tags() {
+		delete(s.tagIndex[tag.Name()], rowID)
+	}
+	for key := range s.items[rowID].Meta() {
+		delete(s.metaIndex[key], rowID)
+	}
+	for _, word := range distinctWords(s.items[rowID].Data()) {
+		delete(s.wordIndex[word], rowID)
+	}
+	for _, connection := range s.items[rowID].Connections() {
+		connectionType, _ := parser.SplitConnection(connection)
+		delete(s.connectionIndex[connectionType], rowID)
+	}
+}
+
+// indexedItemRowIDs uses the tag, meta, word and connection indexes to find the items that have
+// every one of the given tags, metadata keys, words and connection types. It returns nil when
+// there is nothing to narrow by.
+func (s *MemoryStore) indexedItemRowIDs(tagFilters []*TagFilter, metaKeys []string, words []string, connectionTypes []string) map[uint64]bool {
+	var found map[uint64]bool
+	for _, tagFilter := range tagFilters {
+		found = intersectRowIDs(found, rowIDsIn(s.tagIndex[tagFilter.tagName]))
+	}
+	for _, key := range metaKeys {
+		found = intersectRowIDs(found, rowIDsIn(s.metaIndex[key]))
+	}
+	for _, word := range words {
+		found = intersectRowIDs(found, rowIDsIn(s.wordIndex[word]))
+	}
+	for _, connectionType := range connectionTypes {
+		found = intersectRowIDs(found, rowIDsIn(s.connectionIndex[connectionType]))
+	}
+	return found
+}
+
+func rowIDsIn(indexed map[uint64]bool) []uint64 {
+	rowIDs := []uint64{}
+	for rowID := range indexed {
+		rowIDs = append(rowIDs, rowID)
+	}
+	return rowIDs
+}
+
+func (s *MemoryStore) Trash(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID := memoryRowID(item.internalID)
+	if _, ok := s.items[rowID]; !ok {
+		return ErrNotFound
+	}
+	item.trashedAt = time.Unix(time.Now().Unix(), 0)
+	s.removeFromIndexes(rowID)
+	delete(s.items, rowID)
+	s.removeFromTimeline(rowID)
+	s.trash[rowID] = copyItem(item)
+	return nil
+}
+
+func (s *MemoryStore) ListTrash() ([]*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []*Item{}
+	for _, item := range s.trash {
+		items = append(items, copyItem(item))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].TrashedAt().Equal(items[j].TrashedAt()) {
+			return memoryRowID(items[i].internalID) < memoryRowID(items[j].internalID)
+		}
+		return items[i].TrashedAt().Before(items[j].TrashedAt())
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) FindAllTrash(id string) ([]*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []*Item{}
+	for _, item := range s.trash {
+		if strings.HasPrefix(item.ID(), id) {
+			items = append(items, copyItem(item))
+		}
+	}
+	if len(items) < 1 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID() < items[j].ID()
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) Restore(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID := memoryRowID(item.internalID)
+	trashed, ok := s.trash[rowID]
+	if !ok {
+		return ErrNotFound
+	}
+	for _, existing := range s.items {
+		if existing.ID() == trashed.ID() {
+			return ErrAlreadyExists
+		}
+	}
+	delete(s.trash, rowID)
+	restored := copyItem(trashed)
+	restored.trashedAt = time.Time{}
+	s.items[rowID] = restored
+	s.insertIntoTimeline(rowID)
+	s.addToIndexes(rowID)
+	item.trashedAt = time.Time{}
+	return nil
+}
+
+func (s *MemoryStore) DeleteTrash(item *Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID := memoryRowID(item.internalID)
+	if _, ok := s.trash[rowID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.trash, rowID)
+	return nil
+}
+
+func (s *MemoryStore) FindTag(name string) (*Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *tag
+	return &found, nil
+}
+
+func (s *MemoryStore) SaveTag(tag *Tag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.tags[tag.Name()]; ok {
+		tag.internalID = existing.internalID
+		return nil
+	}
+	s.lastTagRowID++
+	tag.internalID = fmt.Sprintf("%d", s.lastTagRowID)
+	saved := *tag
+	s.tags[tag.Name()] = &saved
+	return nil
+}
+
+func (s *MemoryStore) ListTags() ([]*Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outputTags := []*Tag{}
+	for _, tag := range s.tags {
+		found := *tag
+		outputTags = append(outputTags, &found)
+	}
+	sort.Slice(outputTags, func(i, j int) bool {
+		if outputTags[i].CreatedAt().Equal(outputTags[j].CreatedAt()) {
+			return memoryRowID(outputTags[i].internalID) < memoryRowID(outputTags[j].internalID)
+		}
+		return outputTags[i].CreatedAt().Before(outputTags[j].CreatedAt())
+	})
+	return outputTags, nil
+}
+
+func (s *MemoryStore) SaveGroup(group *Group) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rowID uint64
+	if group.internalID != "" {
+		var err error
+		rowID, err = strconv.ParseUint(group.internalID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.groups[rowID]; !ok {
+			return ErrNotFound
+		}
+	}
+
+	for existingRowID, existing := range s.groups {
+		if existing.Name == group.Name && existingRowID != rowID {
+			return ErrAlreadyExists
+		}
+	}
+
+	if rowID == 0 {
+		s.lastGroupRowID++
+		rowID = s.lastGroupRowID
+	}
+	group.internalID = fmt.Sprintf("%d", rowID)
+	saved := *group
+	s.groups[rowID] = &saved
+	return nil
+}
+
+func (s *MemoryStore) DeleteGroup(group *Group) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID, err := strconv.ParseUint(group.internalID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if _, ok := s.groups[rowID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.groups, rowID)
+	return nil
+}
+
+func (s *MemoryStore) ListGroups() ([]*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outputGroups := []*Group{}
+	for _, group := range s.groups {
+		found := *group
+		outputGroups = append(outputGroups, &found)
+	}
+	sort.Slice(outputGroups, func(i, j int) bool {
+		if outputGroups[i].CreatedAt.Equal(outputGroups[j].CreatedAt) {
+			return memoryRowID(outputGroups[i].internalID) < memoryRowID(outputGroups[j].internalID)
+		}
+		return outputGroups[i].CreatedAt.Before(outputGroups[j].CreatedAt)
+	})
+	return outputGroups, nil
+}
+
+func (s *MemoryStore) FindGroupByName(name string) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, group := range s.groups {
+		if group.Name == name {
+			found := *group
+			return &found, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) SaveRevision(revision *Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRevisionRowID++
+	revision.internalID = fmt.Sprintf("%d", s.lastRevisionRowID)
+	saved := *revision
+	s.revisions = append(s.revisions, &saved)
+	return nil
+}
+
+func (s *MemoryStore) ListRevisions(itemID string) ([]*Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revisions := []*Revision{}
+	for _, revision := range s.revisions {
+		if revision.ItemID == itemID {
+			found := *revision
+			revisions = append(revisions, &found)
+		}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].At.Before(revisions[j].At)
+	})
+	return revisions, nil
+}
+
+func (s *MemoryStore) SaveOperation(operation *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastOperationRowID++
+	operation.internalID = fmt.Sprintf("%d", s.lastOperationRowID)
+	saved := *operation
+	s.operations = append(s.operations, &saved)
+	return nil
+}
+
+func (s *MemoryStore) ListOperations() ([]*Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	operations := []*Operation{}
+	for i := len(s.operations) - 1; i >= 0; i-- {
+		found := *s.operations[i]
+		operations = append(operations, &found)
+	}
+	return operations, nil
+}
+
+func (s *MemoryStore) DeleteOperation(operation *Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.operations {
+		if existing.internalID == operation.internalID {
+			s.operations = append(s.operations[:i:i], s.operations[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *MemoryStore) SaveInterval(interval *Interval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rowID uint64
+	if interval.internalID != "" {
+		var err error
+		rowID, err = strconv.ParseUint(interval.internalID, 10, 64)
+		if err != nil {
+			return ErrNotFound
+		}
+		if _, ok := s.intervals[rowID]; !ok {
+			return ErrNotFound
+		}
+	} else {
+		s.lastIntervalRowID++
+		rowID = s.lastIntervalRowID
+		interval.internalID = fmt.Sprintf("%d", rowID)
+	}
+	saved := *interval
+	s.intervals[rowID] = &saved
+	return nil
+}
+
+func (s *MemoryStore) ListIntervals(itemID string) ([]*Interval, error) {
+	return s.findIntervals(func(interval *Interval) bool { return interval.ItemID == itemID }), nil
+}
+
+func (s *MemoryStore) ListRunningIntervals() ([]*Interval, error) {
+	return s.findIntervals((*Interval).Running), nil
+}
+
+func (s *MemoryStore) ListIntervalsBetween(start, end time.Time) ([]*Interval, error) {
+	return s.findIntervals(func(interval *Interval) bool {
+		return interval.Start.Unix() < end.Unix() && (interval.Running() || interval.End.Unix() > start.Unix())
+	}), nil
+}
+
+func (s *MemoryStore) findIntervals(match func(interval *Interval) bool) []*Interval {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowIDs := []uint64{}
+	for rowID, interval := range s.intervals {
+		if match(interval) {
+			rowIDs = append(rowIDs, rowID)
+		}
+	}
+	// in the order they were saved, to break ties between intervals starting together
+	sort.Slice(rowIDs, func(i, j int) bool { return rowIDs[i] < rowIDs[j] })
+
+	intervals := []*Interval{}
+	for _, rowID := range rowIDs {
+		found := *s.intervals[rowID]
+		intervals = append(intervals, &found)
+	}
+	sort.SliceStable(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+	return intervals
+}
+
+func (s *MemoryStore) DeleteInterval(interval *Interval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rowID, err := strconv.ParseUint(interval.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	if _, ok := s.intervals[rowID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.intervals, rowID)
+	return nil
+}
+
+func (s *MemoryStore) WithContext(ctx context.Context) Store {
+	return &MemoryStore{memoryData: s.memoryData, ctx: ctx, inTransaction: s.inTransaction}
+}
+
+// copyItem makes sure callers never share an Item with the store. Derived metadata is
+// dropped so it gets regenerated from the data.
+func copyItem(item *Item) *Item {
+	copied := *item
+	copied.tags = nil
+	copied.connections = nil
+	copied.meta = nil
+	return &copied
+}
+
+func memoryRowID(internalID string) uint64 {
+	rowID, _ := strconv.ParseUint(internalID, 10, 64)
+	return rowID
+}
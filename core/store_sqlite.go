@@ -0,0 +1,1113 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations are applied in order, tracked with the database's user_version.
+// Only ever append to this list.
+var sqliteMigrations = []string{
+	`CREATE TABLE items (
+		row_id      INTEGER PRIMARY KEY AUTOINCREMENT,
+		id          TEXT    NOT NULL UNIQUE,
+		next_id     TEXT    NOT NULL DEFAULT '',
+		previous_id TEXT    NOT NULL DEFAULT '',
+		data        TEXT    NOT NULL DEFAULT '',
+		status      TEXT    NOT NULL DEFAULT '',
+		datetime    INTEGER NOT NULL,
+		kind        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX items_datetime ON items (datetime);
+	CREATE INDEX items_status ON items (status);
+	CREATE INDEX items_kind ON items (kind);
+
+	CREATE TABLE item_tags (
+		item_row_id INTEGER NOT NULL,
+		name        TEXT    NOT NULL,
+		PRIMARY KEY (item_row_id, name)
+	);
+	CREATE INDEX item_tags_name ON item_tags (name);
+
+	CREATE TABLE tags (
+		row_id     INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT    NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		type       TEXT    NOT NULL DEFAULT ''
+	);
+	CREATE INDEX tags_created_at ON tags (created_at);
+
+	CREATE TABLE saved_groups (
+		row_id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		name          TEXT    NOT NULL UNIQUE,
+		filter_string TEXT    NOT NULL DEFAULT '',
+		created_at    INTEGER NOT NULL
+	);
+	CREATE INDEX saved_groups_created_at ON saved_groups (created_at);`,
+
+	`CREATE TABLE item_words (
+		item_row_id INTEGER NOT NULL,
+		word        TEXT    NOT NULL,
+		PRIMARY KEY (item_row_id, word)
+	);
+	CREATE INDEX item_words_word ON item_words (word);`,
+
+	// trashed items stay in the items table, but are hidden from everything except the trash
+	`ALTER TABLE items ADD COLUMN trashed_at INTEGER;
+	CREATE INDEX items_trashed_at ON items (trashed_at);`,
+
+	`CREATE TABLE revisions (
+		row_id    INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id   TEXT    NOT NULL,
+		at        INTEGER NOT NULL,
+		field     TEXT    NOT NULL,
+		old_value TEXT    NOT NULL DEFAULT '',
+		new_value TEXT    NOT NULL DEFAULT ''
+	);
+	CREATE INDEX revisions_item_id ON revisions (item_id);`,
+
+	`CREATE TABLE operations (
+		row_id  INTEGER PRIMARY KEY AUTOINCREMENT,
+		name    TEXT    NOT NULL,
+		at      INTEGER NOT NULL,
+		changes TEXT    NOT NULL
+	);`,
+
+	`ALTER TABLE items ADD COLUMN due INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX items_due ON items (due);`,
+
+	`ALTER TABLE items ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX items_priority ON items (priority);`,
+
+	`CREATE TABLE intervals (
+		row_id   INTEGER PRIMARY KEY AUTOINCREMENT,
+		item_id  TEXT    NOT NULL,
+		start_at INTEGER NOT NULL,
+		end_at   INTEGER
+	);
+	CREATE INDEX intervals_item_id ON intervals (item_id);
+	CREATE INDEX intervals_end_at ON intervals (end_at);`,
+
+	`ALTER TABLE items ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE items ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE items ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE items ADD COLUMN completed_at INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX items_created_at ON items (created_at);
+	CREATE INDEX items_updated_at ON items (updated_at);
+	CREATE INDEX items_completed_at ON items (completed_at);`,
+
+	`ALTER TABLE items ADD COLUMN custom_kind TEXT NOT NULL DEFAULT '';
+	CREATE INDEX items_custom_kind ON items (custom_kind);`,
+
+	`CREATE TABLE item_meta (
+		item_row_id INTEGER NOT NULL,
+		key         TEXT    NOT NULL,
+		value       TEXT    NOT NULL,
+		PRIMARY KEY (item_row_id, key)
+	);
+	CREATE INDEX item_meta_key_value ON item_meta (key, value);`,
+
+	`CREATE TABLE item_connections (
+		item_row_id  INTEGER NOT NULL,
+		type         TEXT    NOT NULL,
+		connected_id TEXT    NOT NULL,
+		PRIMARY KEY (item_row_id, type, connected_id)
+	);
+	CREATE INDEX item_connections_type ON item_connections (type);`,
+}
+
+// sqliteBackfills fill in data for a migration that can't be done in SQL alone, keyed by
+// the version the migration takes the database to
+var sqliteBackfills = map[int]func(s *SQLiteStore) error{
+	2: func(s *SQLiteStore) error {
+		return s.backfillFromData(s.saveItemWords)
+	},
+	12: func(s *SQLiteStore) error {
+		return s.backfillFromData(s.saveItemMeta)
+	},
+	13: func(s *SQLiteStore) error {
+		return s.backfillFromData(s.saveItemConnections)
+	},
+}
+
+// backfillFromData calls save for every item, with only the data of the item loaded
+func (s *SQLiteStore) backfillFromData(save func(rowID int64, item *Item) error) error {
+	// only select columns that exist at every version, later migrations add more
+	rows, err := s.conn.Query("SELECT row_id, data FROM items")
+	if err != nil {
+		return err
+	}
+	items := map[int64]*Item{}
+	for rows.Next() {
+		var rowID int64
+		item := &Item{}
+		err = rows.Scan(&rowID, &item.data)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		items[rowID] = item
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	for rowID, item := range items {
+		err = save(rowID, item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const sqliteItemColumns = "row_id, id, next_id, previous_id, data, status, datetime, kind, custom_kind, due, priority, recurrence, created_at, updated_at, completed_at, trashed_at"
+
+// sqliteConn is satisfied by both *sql.DB and *sql.Tx, so every query can run either
+// directly or as part of a transaction
+type sqliteConn interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type SQLiteStore struct {
+	db   *sql.DB
+	conn sqliteConn
+	tx   *sql.Tx // only set inside a Transaction
+	ctx  context.Context
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite only allows a single writer, so don't let the pool fight over the file
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db, conn: db}
+	err = store.migrate()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	var version int
+	err := s.db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(sqliteMigrations[i])
+		if err == nil && sqliteBackfills[i+1] != nil {
+			err = sqliteBackfills[i+1](&SQLiteStore{db: s.db, conn: tx, tx: tx})
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to migrate sqlite store to version %d: %w", i+1, err)
+		}
+		// pragmas can't take bound parameters
+		_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open is a no-op, the database is held open from NewSQLiteStore until Close
+func (s *SQLiteStore) Open() error {
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	if s.tx != nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Transaction runs f against a store where every operation happens in a single sql
+// transaction, committed only if f returns nil. Nested transactions join the outer one.
+func (s *SQLiteStore) Transaction(f func(tx Store) error) error {
+	if s.tx != nil {
+		return f(s)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = f(&SQLiteStore{db: s.db, conn: tx, tx: tx, ctx: s.ctx})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) FindAll(id string) ([]*Item, error) {
+	rows, err := s.conn.Query("SELECT "+sqliteItemColumns+" FROM items WHERE substr(id, 1, length(?)) = ? AND trashed_at IS NULL ORDER BY id", id, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, ErrNotFound
+	}
+	return items, nil
+}
+
+func (s *SQLiteStore) Delete(item *Item) error {
+	rowID, err := strconv.ParseInt(item.internalID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return s.deleteItemRow(rowID, "trashed_at IS NULL")
+}
+
+// deleteItemRow deletes an item, and what indexes it, if it matches the extra condition
+func (s *SQLiteStore) deleteItemRow(rowID int64, condition string) error {
+	return s.Transaction(func(tx Store) error {
+		sqliteTx := tx.(*SQLiteStore)
+		res, err := sqliteTx.conn.Exec("DELETE FROM items WHERE row_id = ? AND "+condition, rowID)
+		if err != nil {
+			return err
+		}
+		err = sqliteAffectedOne(res)
+		if err != nil {
+			return err
+		}
+		for _, index := range []string{"item_tags", "item_meta", "item_connections", "item_words"} {
+			_, err = sqliteTx.conn.Exec("DELETE FROM "+index+" WHERE item_row_id = ?", rowID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Save writes the item and its indexes in a single transaction, so they never get out of step
+func (s *SQLiteStore) Save(item *Item) error {
+	var rowID int64
+	if item.internalID != "" {
+		var err error
+		rowID, err = strconv.ParseInt(item.internalID, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.Transaction(func(tx Store) error {
+		sqliteTx := tx.(*SQLiteStore)
+		if rowID != 0 {
+			res, err := sqliteTx.conn.Exec("UPDATE items SET id = ?, next_id = ?, previous_id = ?, data = ?, status = ?, datetime = ?, kind = ?, custom_kind = ?, due = ?, priority = ?, recurrence = ?, created_at = ?, updated_at = ?, completed_at = ? WHERE row_id = ? AND trashed_at IS NULL",
+				item.ID(), item.NextID(), item.PreviousID(), item.Data(), item.Status(), item.Time().Unix(), int64(item.Kind()), item.CustomKind(), unixOrZero(item.Due()), int64(item.Priority()), item.Recurrence(),
+				unixOrZero(item.CreatedAt()), unixOrZero(item.UpdatedAt()), unixOrZero(item.CompletedAt()), rowID)
+			if err != nil {
+				return sqliteError(err)
+			}
+			err = sqliteAffectedOne(res)
+			if err != nil {
+				return err
+			}
+		} else {
+			res, err := sqliteTx.conn.Exec("INSERT INTO items (id, next_id, previous_id, data, status, datetime, kind, custom_kind, due, priority, recurrence, created_at, updated_at, completed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				item.ID(), item.NextID(), item.PreviousID(), item.Data(), item.Status(), item.Time().Unix(), int64(item.Kind()), item.CustomKind(), unixOrZero(item.Due()), int64(item.Priority()), item.Recurrence(),
+				unixOrZero(item.CreatedAt()), unixOrZero(item.UpdatedAt()), unixOrZero(item.CompletedAt()))
+			if err != nil {
+				return sqliteError(err)
+			}
+			rowID, err = res.LastInsertId()
+			if err != nil {
+				return err
+			}
+		}
+		err := sqliteTx.saveItemTags(rowID, item)
+		if err != nil {
+			return err
+		}
+		err = sqliteTx.saveItemMeta(rowID, item)
+		if err != nil {
+			return err
+		}
+		err = sqliteTx.saveItemConnections(rowID, item)
+		if err != nil {
+			return err
+		}
+		return sqliteTx.saveItemWords(rowID, item)
+	})
+	if err != nil {
+		return err
+	}
+	item.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) saveItemTags(rowID int64, item *Item) error {
+	_, err := s.conn.Exec("DELETE FROM item_tags WHERE item_row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	tokenizer := &parser.Tokenizer{}
+	tokenResult, err := tokenizer.Tokenize(item.Data())
+	if err != nil {
+		return err
+	}
+	for _, tag := range tokenResult.Tags {
+		_, err = s.conn.Exec("INSERT INTO item_tags (item_row_id, name) VALUES (?, ?)", rowID, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) saveItemMeta(rowID int64, item *Item) error {
+	_, err := s.conn.Exec("DELETE FROM item_meta WHERE item_row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	for key, value := range item.Meta() {
+		_, err = s.conn.Exec("INSERT INTO item_meta (item_row_id, key, value) VALUES (?, ?, ?)", rowID, key, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) saveItemConnections(rowID int64, item *Item) error {
+	_, err := s.conn.Exec("DELETE FROM item_connections WHERE item_row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	for _, connection := range item.Connections() {
+		connectionType, connectedID := parser.SplitConnection(connection)
+		_, err = s.conn.Exec("INSERT OR IGNORE INTO item_connections (item_row_id, type, connected_id) VALUES (?, ?, ?)", rowID, connectionType, connectedID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) saveItemWords(rowID int64, item *Item) error {
+	_, err := s.conn.Exec("DELETE FROM item_words WHERE item_row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	for _, word := range distinctWords(item.Data()) {
+		_, err = s.conn.Exec("INSERT INTO item_words (item_row_id, word) VALUES (?, ?)", rowID, word)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListFilters(filters []filter.Filter) ([]*Item, error) {
+	options, filters := findListOptions(filters)
+	conditions, args, rest := s.conditionsForFilters(filters)
+	conditions = append([]string{"trashed_at IS NULL"}, conditions...)
+
+	query := "SELECT " + sqliteItemColumns + " FROM items WHERE " + strings.Join(conditions, " AND ")
+	query += " ORDER BY " + sqliteOrderBy(options)
+	if len(rest) == 0 && options.paged() {
+		// every filter is in the query, so it can page the results too
+		query += " LIMIT ? OFFSET ?"
+		limit := options.limit
+		if limit == 0 {
+			limit = -1
+		}
+		args = append(args, limit, options.offset)
+		options = listOptions{}
+	}
+
+	rows, err := s.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	outputItems := []*Item{}
+	for _, item := range items {
+		match := true
+		for _, filter := range rest {
+			ok, err := filter.Match(MatchableItem{Item: item})
+			if !ok || err != nil {
+				match = false
+				break
+			}
+		}
+		if match {
+			outputItems = append(outputItems, item)
+		}
+	}
+	return options.page(outputItems), nil
+}
+
+// sqliteOrderBy sorts items by the options' sorts, then by time
+func sqliteOrderBy(options listOptions) string {
+	direction := ""
+	if options.descendingTime() {
+		direction = " DESC"
+	}
+
+	terms := []string{}
+	for _, sortFilter := range options.sorts {
+		sortDirection := ""
+		if sortFilter.descending {
+			sortDirection = " DESC"
+		}
+		switch sortFilter.field {
+		case TimeSort:
+			terms = append(terms, "datetime"+sortDirection)
+		case StatusSort:
+			terms = append(terms, "CASE status WHEN 'waiting' THEN 0 WHEN 'done' THEN 2 WHEN 'skipped' THEN 3 WHEN 'bumped' THEN 4 WHEN 'none' THEN 5 ELSE 1 END"+sortDirection, "status"+sortDirection)
+		case KindSort:
+			terms = append(terms, "CASE WHEN custom_kind != '' THEN custom_kind WHEN kind = 2 THEN 'note' ELSE 'task' END"+sortDirection)
+		case PrioritySort:
+			terms = append(terms, "CASE priority WHEN 0 THEN 5 ELSE priority END"+sortDirection)
+		case TagSort:
+			// items without tags go last
+			firstTag := "(SELECT MIN(name) FROM item_tags WHERE item_row_id = items.row_id)"
+			terms = append(terms, firstTag+" IS NULL"+sortDirection, firstTag+sortDirection)
+		}
+	}
+	terms = append(terms, "datetime"+direction, "row_id"+direction)
+	return strings.Join(terms, ", ")
+}
+
+// conditionsForFilters pushes the filters that map directly onto an indexed column down into SQL.
+// Any filters that can't be expressed that way are returned to be matched in memory.
+func (s *SQLiteStore) conditionsForFilters(filters []filter.Filter) ([]string, []interface{}, []filter.Filter) {
+	conditions := []string{}
+	args := []interface{}{}
+	rest := []filter.Filter{}
+	usedDateFilter := false
+
+	for _, f := range filters {
+		switch typed := f.(type) {
+		case *DateFilter:
+			if typed.comparison == filter.FilterNe {
+				conditions = append(conditions, "datetime NOT BETWEEN ? AND ?")
+				args = append(args, typed.timespan.Start.Unix(), typed.timespan.End.Unix())
+				continue
+			}
+			if usedDateFilter {
+				rest = append(rest, f)
+				continue
+			}
+			usedDateFilter = true
+			conditions = append(conditions, "datetime BETWEEN ? AND ?")
+			args = append(args, typed.timespan.Start.Unix(), typed.timespan.End.Unix())
+		case *DueFilter:
+			condition := "due != 0 AND due BETWEEN ? AND ?"
+			if typed.comparison == filter.FilterNe {
+				condition = "NOT (" + condition + ")"
+			}
+			conditions = append(conditions, condition)
+			args = append(args, typed.timespan.Start.Unix(), typed.timespan.End.Unix())
+		case *TimestampFilter:
+			column := typed.field + "_at"
+			condition := column + " != 0 AND " + column + " BETWEEN ? AND ?"
+			if typed.comparison == filter.FilterNe {
+				condition = "NOT (" + condition + ")"
+			}
+			conditions = append(conditions, condition)
+			args = append(args, typed.timespan.Start.Unix(), typed.timespan.End.Unix())
+		case *PriorityFilter:
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "priority = ?")
+			case filter.FilterNe:
+				conditions = append(conditions, "priority != ?")
+			case filter.FilterGt:
+				conditions = append(conditions, "priority != 0 AND priority > ?")
+			case filter.FilterLt:
+				conditions = append(conditions, "priority != 0 AND priority < ?")
+			default:
+				rest = append(rest, f)
+				continue
+			}
+			args = append(args, int64(typed.priority))
+		case *StatusFilter:
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(typed.statuses)), ", ")
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "status IN ("+placeholders+")")
+			case filter.FilterNe:
+				conditions = append(conditions, "status NOT IN ("+placeholders+")")
+			default:
+				rest = append(rest, f)
+				continue
+			}
+			for _, status := range typed.statuses {
+				args = append(args, status)
+			}
+		case *KindFilter:
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "kind = ? AND custom_kind = ?")
+			case filter.FilterNe:
+				conditions = append(conditions, "NOT (kind = ? AND custom_kind = ?)")
+			default:
+				rest = append(rest, f)
+				continue
+			}
+			args = append(args, int64(typed.matchKind), typed.customKind)
+		case *TagFilter:
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "row_id IN (SELECT item_row_id FROM item_tags WHERE name = ?)")
+			case filter.FilterNe:
+				conditions = append(conditions, "row_id NOT IN (SELECT item_row_id FROM item_tags WHERE name = ?)")
+			default:
+				rest = append(rest, f)
+				continue
+			}
+			args = append(args, typed.tagName)
+		case *ConnectionFilter:
+			conditions = append(conditions, "row_id IN (SELECT item_row_id FROM item_connections WHERE type = ?)")
+			args = append(args, typed.connectionType)
+		case *MetaFilter:
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "row_id IN (SELECT item_row_id FROM item_meta WHERE key = ? AND value = ?)")
+				args = append(args, typed.key, typed.value)
+			case filter.FilterNe:
+				conditions = append(conditions, "row_id NOT IN (SELECT item_row_id FROM item_meta WHERE key = ? AND value = ?)")
+				args = append(args, typed.key, typed.value)
+			default:
+				// the index finds items with the key, but comparing values needs parsing them
+				conditions = append(conditions, "row_id IN (SELECT item_row_id FROM item_meta WHERE key = ?)")
+				args = append(args, typed.key)
+				rest = append(rest, f)
+			}
+		case *TextFilter:
+			words := distinctWords(typed.text)
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(words)), ", ")
+			withAllWords := "SELECT item_row_id FROM item_words WHERE word IN (" + placeholders + ") GROUP BY item_row_id HAVING COUNT(*) = ?"
+			switch typed.comparison {
+			case filter.FilterEq:
+				conditions = append(conditions, "row_id IN ("+withAllWords+")")
+			case filter.FilterNe:
+				conditions = append(conditions, "row_id NOT IN ("+withAllWords+")")
+			case filter.FilterLike:
+				// the index finds items with all the words, but they still need checking for the phrase
+				conditions = append(conditions, "row_id IN ("+withAllWords+")")
+				rest = append(rest, f)
+			default:
+				rest = append(rest, f)
+				continue
+			}
+			for _, word := range words {
+				args = append(args, word)
+			}
+			args = append(args, len(words))
+		case *filter.And, *filter.Or, *filter.Not:
+			condition, compositeArgs, ok := s.compositeCondition(f)
+			if !ok {
+				rest = append(rest, f)
+				continue
+			}
+			conditions = append(conditions, condition)
+			args = append(args, compositeArgs...)
+		default:
+			rest = append(rest, f)
+		}
+	}
+	return conditions, args, rest
+}
+
+// compositeCondition is the condition for an and, or or not filter, as long as every filter
+// inside it can be pushed down into SQL
+func (s *SQLiteStore) compositeCondition(f filter.Filter) (string, []interface{}, bool) {
+	var inner []filter.Filter
+	separator := " AND "
+	switch typed := f.(type) {
+	case *filter.And:
+		inner = typed.Filters
+	case *filter.Or:
+		inner = typed.Filters
+		separator = " OR "
+	case *filter.Not:
+		inner = []filter.Filter{typed.Filter}
+	}
+
+	parts := []string{}
+	args := []interface{}{}
+	for _, innerFilter := range inner {
+		conditions, innerArgs, rest := s.conditionsForFilters([]filter.Filter{innerFilter})
+		if len(rest) > 0 || len(conditions) == 0 {
+			return "", nil, false
+		}
+		parts = append(parts, "("+strings.Join(conditions, " AND ")+")")
+		args = append(args, innerArgs...)
+	}
+	if _, ok := f.(*filter.Not); ok {
+		return "NOT " + parts[0], args, true
+	}
+	return "(" + strings.Join(parts, separator) + ")", args, true
+}
+
+func (s *SQLiteStore) scanItems(rows *sql.Rows) ([]*Item, error) {
+	defer rows.Close()
+	items := []*Item{}
+	for rows.Next() {
+		var rowID, datetime, kind, due, priority, createdAt, updatedAt, completedAt int64
+		var trashedAt sql.NullInt64
+		item := &Item{}
+		err := rows.Scan(&rowID, &item.id, &item.nextID, &item.previousID, &item.data, &item.status, &datetime, &kind, &item.customKind, &due, &priority, &item.recurrence,
+			&createdAt, &updatedAt, &completedAt, &trashedAt)
+		if err != nil {
+			return nil, err
+		}
+		item.internalID = fmt.Sprintf("%d", rowID)
+		item.datetime = time.Unix(datetime, 0)
+		item.kind = Kind(kind)
+		item.due = timeOrZero(due)
+		item.priority = Priority(priority)
+		item.createdAt = timeOrZero(createdAt)
+		item.updatedAt = timeOrZero(updatedAt)
+		item.completedAt = timeOrZero(completedAt)
+		if trashedAt.Valid {
+			item.trashedAt = time.Unix(trashedAt.Int64, 0)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *SQLiteStore) Trash(item *Item) error {
+	rowID, err := strconv.ParseInt(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	trashedAt := time.Now().Unix()
+	res, err := s.conn.Exec("UPDATE items SET trashed_at = ? WHERE row_id = ? AND trashed_at IS NULL", trashedAt, rowID)
+	if err != nil {
+		return err
+	}
+	err = sqliteAffectedOne(res)
+	if err != nil {
+		return err
+	}
+	item.trashedAt = time.Unix(trashedAt, 0)
+	return nil
+}
+
+func (s *SQLiteStore) ListTrash() ([]*Item, error) {
+	rows, err := s.conn.Query("SELECT " + sqliteItemColumns + " FROM items WHERE trashed_at IS NOT NULL ORDER BY trashed_at, row_id")
+	if err != nil {
+		return nil, err
+	}
+	return s.scanItems(rows)
+}
+
+func (s *SQLiteStore) FindAllTrash(id string) ([]*Item, error) {
+	rows, err := s.conn.Query("SELECT "+sqliteItemColumns+" FROM items WHERE substr(id, 1, length(?)) = ? AND trashed_at IS NOT NULL ORDER BY id", id, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) < 1 {
+		return nil, ErrNotFound
+	}
+	return items, nil
+}
+
+func (s *SQLiteStore) Restore(item *Item) error {
+	rowID, err := strconv.ParseInt(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	res, err := s.conn.Exec("UPDATE items SET trashed_at = NULL WHERE row_id = ? AND trashed_at IS NOT NULL", rowID)
+	if err != nil {
+		return err
+	}
+	err = sqliteAffectedOne(res)
+	if err != nil {
+		return err
+	}
+	item.trashedAt = time.Time{}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteTrash(item *Item) error {
+	rowID, err := strconv.ParseInt(item.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	return s.deleteItemRow(rowID, "trashed_at IS NOT NULL")
+}
+
+func (s *SQLiteStore) FindTag(name string) (*Tag, error) {
+	var rowID, createdAt int64
+	tag := &Tag{}
+	err := s.conn.QueryRow("SELECT row_id, name, created_at FROM tags WHERE name = ?", name).Scan(&rowID, &tag.name, &createdAt)
+	if err != nil {
+		return nil, sqliteError(err)
+	}
+	tag.internalID = fmt.Sprintf("%d", rowID)
+	tag.createdAt = time.Unix(createdAt, 0)
+	return tag, nil
+}
+
+func (s *SQLiteStore) SaveTag(tag *Tag) error {
+	res, err := s.conn.Exec("INSERT INTO tags (name, created_at, type) VALUES (?, ?, ?)", tag.Name(), tag.CreatedAt().Unix(), tag.TagType())
+	if err != nil {
+		if sqliteError(err) == ErrAlreadyExists {
+			found, err := s.FindTag(tag.Name())
+			if err != nil {
+				return err
+			}
+			tag.internalID = found.internalID
+			return nil
+		}
+		return err
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	tag.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) ListTags() ([]*Tag, error) {
+	outputTags := []*Tag{}
+	rows, err := s.conn.Query("SELECT row_id, name, created_at FROM tags ORDER BY created_at, row_id")
+	if err != nil {
+		return outputTags, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID, createdAt int64
+		tag := &Tag{}
+		err := rows.Scan(&rowID, &tag.name, &createdAt)
+		if err != nil {
+			return outputTags, err
+		}
+		tag.internalID = fmt.Sprintf("%d", rowID)
+		tag.createdAt = time.Unix(createdAt, 0)
+		outputTags = append(outputTags, tag)
+	}
+	return outputTags, rows.Err()
+}
+
+func (s *SQLiteStore) SaveGroup(group *Group) error {
+	if group.internalID != "" {
+		rowID, err := strconv.ParseInt(group.internalID, 10, 64)
+		if err != nil {
+			return err
+		}
+		res, err := s.conn.Exec("UPDATE saved_groups SET name = ?, filter_string = ?, created_at = ? WHERE row_id = ?",
+			group.Name, group.FilterString, group.CreatedAt.Unix(), rowID)
+		if err != nil {
+			return sqliteError(err)
+		}
+		return sqliteAffectedOne(res)
+	}
+	res, err := s.conn.Exec("INSERT INTO saved_groups (name, filter_string, created_at) VALUES (?, ?, ?)",
+		group.Name, group.FilterString, group.CreatedAt.Unix())
+	if err != nil {
+		return sqliteError(err)
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	group.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) DeleteGroup(group *Group) error {
+	rowID, err := strconv.ParseInt(group.internalID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	res, err := s.conn.Exec("DELETE FROM saved_groups WHERE row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	return sqliteAffectedOne(res)
+}
+
+func (s *SQLiteStore) ListGroups() ([]*Group, error) {
+	outputGroups := []*Group{}
+	rows, err := s.conn.Query("SELECT row_id, name, filter_string, created_at FROM saved_groups ORDER BY created_at, row_id")
+	if err != nil {
+		return outputGroups, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		group, err := s.scanGroup(rows)
+		if err != nil {
+			return outputGroups, err
+		}
+		outputGroups = append(outputGroups, group)
+	}
+	return outputGroups, rows.Err()
+}
+
+func (s *SQLiteStore) FindGroupByName(name string) (*Group, error) {
+	row := s.conn.QueryRow("SELECT row_id, name, filter_string, created_at FROM saved_groups WHERE name = ?", name)
+	group, err := s.scanGroup(row)
+	if err != nil {
+		return nil, sqliteError(err)
+	}
+	return group, nil
+}
+
+func (s *SQLiteStore) scanGroup(row interface{ Scan(...interface{}) error }) (*Group, error) {
+	var rowID, createdAt int64
+	group := &Group{}
+	err := row.Scan(&rowID, &group.Name, &group.FilterString, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	group.internalID = fmt.Sprintf("%d", rowID)
+	group.CreatedAt = time.Unix(createdAt, 0)
+	return group, nil
+}
+
+func (s *SQLiteStore) SaveRevision(revision *Revision) error {
+	res, err := s.conn.Exec("INSERT INTO revisions (item_id, at, field, old_value, new_value) VALUES (?, ?, ?, ?, ?)",
+		revision.ItemID, revision.At.Unix(), revision.Field, revision.Old, revision.New)
+	if err != nil {
+		return err
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	revision.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) ListRevisions(itemID string) ([]*Revision, error) {
+	revisions := []*Revision{}
+	rows, err := s.conn.Query("SELECT row_id, item_id, at, field, old_value, new_value FROM revisions WHERE item_id = ? ORDER BY at, row_id", itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID, at int64
+		revision := &Revision{}
+		err = rows.Scan(&rowID, &revision.ItemID, &at, &revision.Field, &revision.Old, &revision.New)
+		if err != nil {
+			return nil, err
+		}
+		revision.internalID = fmt.Sprintf("%d", rowID)
+		revision.At = time.Unix(at, 0)
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *SQLiteStore) SaveOperation(operation *Operation) error {
+	changes, err := encodeChanges(operation.Changes)
+	if err != nil {
+		return err
+	}
+	res, err := s.conn.Exec("INSERT INTO operations (name, at, changes) VALUES (?, ?, ?)", operation.Name, operation.At.Unix(), changes)
+	if err != nil {
+		return err
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	operation.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) ListOperations() ([]*Operation, error) {
+	operations := []*Operation{}
+	rows, err := s.conn.Query("SELECT row_id, name, at, changes FROM operations ORDER BY row_id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rowID, at int64
+		var changes string
+		operation := &Operation{}
+		err = rows.Scan(&rowID, &operation.Name, &at, &changes)
+		if err != nil {
+			return nil, err
+		}
+		operation.Changes, err = decodeChanges(changes)
+		if err != nil {
+			return nil, err
+		}
+		operation.internalID = fmt.Sprintf("%d", rowID)
+		operation.At = time.Unix(at, 0)
+		operations = append(operations, operation)
+	}
+	return operations, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteOperation(operation *Operation) error {
+	rowID, err := strconv.ParseInt(operation.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	res, err := s.conn.Exec("DELETE FROM operations WHERE row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	return sqliteAffectedOne(res)
+}
+
+func (s *SQLiteStore) SaveInterval(interval *Interval) error {
+	var end sql.NullInt64
+	if !interval.Running() {
+		end = sql.NullInt64{Int64: interval.End.Unix(), Valid: true}
+	}
+	if interval.internalID != "" {
+		rowID, err := strconv.ParseInt(interval.internalID, 10, 64)
+		if err != nil {
+			return ErrNotFound
+		}
+		res, err := s.conn.Exec("UPDATE intervals SET item_id = ?, start_at = ?, end_at = ? WHERE row_id = ?",
+			interval.ItemID, interval.Start.Unix(), end, rowID)
+		if err != nil {
+			return err
+		}
+		return sqliteAffectedOne(res)
+	}
+	res, err := s.conn.Exec("INSERT INTO intervals (item_id, start_at, end_at) VALUES (?, ?, ?)", interval.ItemID, interval.Start.Unix(), end)
+	if err != nil {
+		return err
+	}
+	rowID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	interval.internalID = fmt.Sprintf("%d", rowID)
+	return nil
+}
+
+func (s *SQLiteStore) ListIntervals(itemID string) ([]*Interval, error) {
+	rows, err := s.conn.Query("SELECT row_id, item_id, start_at, end_at FROM intervals WHERE item_id = ? ORDER BY start_at, row_id", itemID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanIntervals(rows)
+}
+
+func (s *SQLiteStore) ListRunningIntervals() ([]*Interval, error) {
+	rows, err := s.conn.Query("SELECT row_id, item_id, start_at, end_at FROM intervals WHERE end_at IS NULL ORDER BY start_at, row_id")
+	if err != nil {
+		return nil, err
+	}
+	return s.scanIntervals(rows)
+}
+
+func (s *SQLiteStore) ListIntervalsBetween(start, end time.Time) ([]*Interval, error) {
+	rows, err := s.conn.Query("SELECT row_id, item_id, start_at, end_at FROM intervals WHERE start_at < ? AND (end_at IS NULL OR end_at > ?) ORDER BY start_at, row_id",
+		end.Unix(), start.Unix())
+	if err != nil {
+		return nil, err
+	}
+	return s.scanIntervals(rows)
+}
+
+func (s *SQLiteStore) scanIntervals(rows *sql.Rows) ([]*Interval, error) {
+	defer rows.Close()
+	intervals := []*Interval{}
+	for rows.Next() {
+		var rowID, start int64
+		var end sql.NullInt64
+		interval := &Interval{}
+		err := rows.Scan(&rowID, &interval.ItemID, &start, &end)
+		if err != nil {
+			return nil, err
+		}
+		interval.internalID = fmt.Sprintf("%d", rowID)
+		interval.Start = time.Unix(start, 0)
+		if end.Valid {
+			interval.End = time.Unix(end.Int64, 0)
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteInterval(interval *Interval) error {
+	rowID, err := strconv.ParseInt(interval.internalID, 10, 64)
+	if err != nil {
+		return ErrNotFound
+	}
+	res, err := s.conn.Exec("DELETE FROM intervals WHERE row_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+	return sqliteAffectedOne(res)
+}
+
+func (s *SQLiteStore) WithContext(ctx context.Context) Store {
+	return &SQLiteStore{db: s.db, conn: s.conn, tx: s.tx, ctx: ctx}
+}
+
+// sqliteError translates sqlite errors into the store errors shared by all backends
+func sqliteError(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func sqliteAffectedOne(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected < 1 {
+		return ErrNotFound
+	}
+	return nil
+}
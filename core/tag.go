@@ -15,6 +15,10 @@ func NewTag(name string) *Tag {
 	return &Tag{name: name, createdAt: time.Now()}
 }
 
+func (t *Tag) InternalID() string {
+	return t.internalID
+}
+
 func (t *Tag) Name() string {
 	return t.name
 }
@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -32,10 +33,9 @@ func (tp TimeParser) Parse() (*Timespan, error) {
 		return NewTimespan(tp.startTime, tp.startTime), nil
 	case "day":
 		return NewTimespan(tp.startOfDay(tp.startTime), tp.endOfDay(tp.startTime)), nil
-	case "week":
-		return NewTimespan(tp.startOfWeek(tp.startTime), tp.endOfWeek(tp.startTime)), nil
-	case "month":
-		return NewTimespan(tp.startOfMonth(tp.startTime), tp.endOfMonth(tp.startTime)), nil
+	case "week", "month", "quarter", "year":
+		span, _ := tp.period(tp.Input, tp.startTime, 0)
+		return span, nil
 	case "today":
 		return NewTimespan(tp.startOfDay(tp.startTime), tp.endOfDay(tp.startTime)), nil
 	case "tomorrow":
@@ -51,6 +51,12 @@ func (tp TimeParser) Parse() (*Timespan, error) {
 		return tp.nextOccuranceOfWeekday(tp.startTime, weekday, 24), nil
 	}
 
+	// try to parse a time of day, on its own or after a day
+	span, ok := tp.parseTimeOfDay()
+	if ok {
+		return span, nil
+	}
+
 	// try to parse a weekday phrase
 	splitStrings := strings.Split(tp.Input, " ")
 	if len(splitStrings) == 2 {
@@ -66,34 +72,33 @@ func (tp TimeParser) Parse() (*Timespan, error) {
 				return tp.nextOccuranceOfWeekday(tp.endOfWeek(tp.startTime).AddDate(0, 0, 1), weekday, 24), nil
 			}
 		}
-		switch splitStrings[1] {
-		// parse "<offset> <duration>"
-		case "week":
-			switch splitStrings[0] {
-			case "last":
-				lastWeek := tp.startTime.AddDate(0, 0, -7)
-				return NewTimespan(tp.startOfWeek(lastWeek), tp.endOfWeek(lastWeek)), nil
-			case "this":
-				return NewTimespan(tp.startOfWeek(tp.startTime), tp.endOfWeek(tp.startTime)), nil
-			case "next":
-				nextWeek := tp.startTime.AddDate(0, 0, 7)
-				return NewTimespan(tp.startOfWeek(nextWeek), tp.endOfWeek(nextWeek)), nil
+
+		// parse "<offset> <period>"
+		offsets := map[string]int{"last": -1, "this": 0, "next": 1}
+		if offset, ok := offsets[splitStrings[0]]; ok {
+			span, ok := tp.period(splitStrings[1], tp.startTime, offset)
+			if ok {
+				return span, nil
 			}
-		case "month":
+		}
+	}
+
+	if len(splitStrings) == 3 {
+		// parse "start of <period>" or "end of <period>"
+		span, ok := tp.period(splitStrings[2], tp.startTime, 0)
+		if ok && splitStrings[1] == "of" {
 			switch splitStrings[0] {
-			case "last":
-				lastMonth := tp.startTime.AddDate(0, -1, 0)
-				return NewTimespan(tp.startOfMonth(lastMonth), tp.endOfMonth(lastMonth)), nil
-			case "this":
-				return NewTimespan(tp.startOfMonth(tp.startTime), tp.endOfMonth(tp.startTime)), nil
-			case "next":
-				nextMonth := tp.startTime.AddDate(0, 1, 0)
-				return NewTimespan(tp.startOfMonth(nextMonth), tp.endOfMonth(nextMonth)), nil
+			case "start":
+				return NewTimespan(span.Start, tp.endOfDay(span.Start)), nil
+			case "end":
+				return NewTimespan(tp.startOfDay(span.End), span.End), nil
 			}
-		default:
-			return NewTimespan(tp.startTime, tp.startTime), fmt.Errorf("failed to parse time with input: %s", tp.Input)
 		}
 
+		span, ok = tp.parseRelative(splitStrings)
+		if ok {
+			return span, nil
+		}
 	}
 
 	// try to parse a date from a formatted input
@@ -108,9 +113,175 @@ func (tp TimeParser) Parse() (*Timespan, error) {
 		return NewTimespan(tp.startOfDay(found), tp.endOfDay(found)), nil
 	}
 
+	span, ok = tp.parseISOWeek()
+	if ok {
+		return span, nil
+	}
+
 	return NewTimespan(tp.startTime, tp.startTime), fmt.Errorf("failed to parse time with input: %s", tp.Input)
 }
 
+// period is the week, month, quarter or year containing t, moved on by offset of them
+func (tp TimeParser) period(unit string, t time.Time, offset int) (*Timespan, bool) {
+	switch unit {
+	case "week":
+		start := tp.startOfWeek(t).AddDate(0, 0, 7*offset)
+		return NewTimespan(start, tp.endOfWeek(start)), true
+	case "month":
+		start := tp.startOfMonth(t).AddDate(0, offset, 0)
+		return NewTimespan(start, tp.endOfMonth(start)), true
+	case "quarter":
+		start := tp.startOfQuarter(t).AddDate(0, 3*offset, 0)
+		return NewTimespan(start, tp.endOfQuarter(start)), true
+	case "year":
+		start := tp.startOfYear(t).AddDate(offset, 0, 0)
+		return NewTimespan(start, tp.endOfYear(start)), true
+	}
+	return nil, false
+}
+
+// parseRelative parses an amount of time from now, like "in 3 days" or "2 weeks ago". Days and
+// longer are the whole day they land on, while hours and minutes are an exact time.
+func (tp TimeParser) parseRelative(words []string) (*Timespan, bool) {
+	var amount, unit string
+	var sign int
+	switch {
+	case words[0] == "in":
+		amount, unit, sign = words[1], words[2], 1
+	case words[2] == "ago":
+		amount, unit, sign = words[0], words[1], -1
+	default:
+		return nil, false
+	}
+
+	n := 1
+	if amount != "a" && amount != "an" {
+		var err error
+		n, err = strconv.Atoi(amount)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+	}
+	n *= sign
+
+	var at time.Time
+	switch strings.TrimSuffix(unit, "s") {
+	case "minute":
+		at = tp.startTime.Add(time.Duration(n) * time.Minute)
+		return NewTimespan(at, at), true
+	case "hour":
+		at = tp.startTime.Add(time.Duration(n) * time.Hour)
+		return NewTimespan(at, at), true
+	case "day":
+		at = tp.startTime.AddDate(0, 0, n)
+	case "week":
+		at = tp.startTime.AddDate(0, 0, 7*n)
+	case "month":
+		at = tp.startTime.AddDate(0, n, 0)
+	case "year":
+		at = tp.startTime.AddDate(n, 0, 0)
+	default:
+		return nil, false
+	}
+	return NewTimespan(tp.startOfDay(at), tp.endOfDay(at)), true
+}
+
+// parseTimeOfDay parses a time of day like "15:00" or "9am", meaning today, or one after a day
+// like "tomorrow 9am" or "friday 14:30". It runs until the end of that day.
+func (tp TimeParser) parseTimeOfDay() (*Timespan, bool) {
+	day, clock := "today", tp.Input
+	if i := strings.LastIndex(tp.Input, " "); i != -1 {
+		day, clock = tp.Input[:i], tp.Input[i+1:]
+	}
+	hour, minute, ok := parseClock(clock)
+	if !ok {
+		return nil, false
+	}
+	daySpan, err := TimeParser{Input: day, startTime: tp.startTime}.Parse()
+	if err != nil {
+		return nil, false
+	}
+	start := daySpan.Start
+	at := time.Date(start.Year(), start.Month(), start.Day(), hour, minute, 0, 0, start.Location())
+	return NewTimespan(at, tp.endOfDay(at)), true
+}
+
+// parseClock parses a 24 hour time like "14:30", or a 12 hour one like "9am" or "9:30pm"
+func parseClock(input string) (int, int, bool) {
+	for _, layout := range []string{"15:04", "3pm", "3:04pm"} {
+		t, err := time.Parse(layout, strings.ToLower(input))
+		if err == nil {
+			return t.Hour(), t.Minute(), true
+		}
+	}
+	return 0, 0, false
+}
+
+var isoWeekExp = regexp.MustCompile(`^(\d{4})-[Ww](\d{2})$`)
+
+// parseISOWeek parses an ISO week like "2024-W12", from its monday to its sunday
+func (tp TimeParser) parseISOWeek() (*Timespan, bool) {
+	matches := isoWeekExp.FindStringSubmatch(tp.Input)
+	if matches == nil {
+		return nil, false
+	}
+	year, _ := strconv.Atoi(matches[1])
+	week, _ := strconv.Atoi(matches[2])
+
+	// the 4th of january is always in the first week
+	start := tp.startOfWeek(time.Date(year, time.January, 4, 0, 0, 0, 0, tp.startTime.Location())).AddDate(0, 0, 7*(week-1))
+	if isoYear, isoWeek := start.ISOWeek(); isoYear != year || isoWeek != week {
+		return nil, false
+	}
+	return NewTimespan(start, tp.endOfWeek(start)), true
+}
+
+// timeRangeSeparator separates the start and end of a range of times
+const timeRangeSeparator = ".."
+
+func isTimeRange(input string) bool {
+	return strings.Contains(input, timeRangeSeparator)
+}
+
+// ParseRange parses either a single time, or a range of times like "last monday..yesterday"
+// from the start of the first to the end of the second. Leaving out either end leaves the range
+// open on that side.
+func (tp TimeParser) ParseRange() (*Timespan, error) {
+	if !isTimeRange(tp.Input) {
+		return tp.Parse()
+	}
+
+	split := strings.SplitN(tp.Input, timeRangeSeparator, 2)
+	startInput, endInput := strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+	if startInput == "" && endInput == "" {
+		return nil, fmt.Errorf("failed to parse time range with input: %s, it needs a start or an end", tp.Input)
+	}
+
+	timespan := NewTimespan(Timespan{}.EarliestTime(), Timespan{}.LatestTime())
+	if startInput != "" {
+		start, err := TimeParser{Input: startInput, startTime: tp.startTime}.Parse()
+		if err != nil {
+			return nil, err
+		}
+		timespan.Start = start.Start
+	}
+	if endInput != "" {
+		end, err := TimeParser{Input: endInput, startTime: tp.startTime}.Parse()
+		if err != nil {
+			return nil, err
+		}
+		timespan.End = end.End
+		if !end.Start.Equal(tp.startOfDay(end.Start)) {
+			// a time of day like "friday 17:00" ends the range then, not at the end of the day
+			timespan.End = end.Start
+		}
+	}
+	if timespan.Start.After(timespan.End) {
+		return nil, fmt.Errorf("failed to parse time range with input: %s, it ends before it starts", tp.Input)
+	}
+	return timespan, nil
+}
+
 func (tp TimeParser) nextOccuranceOfWeekday(startAt time.Time, weekday time.Weekday, jump time.Duration) *Timespan {
 	startTime := startAt
 	for {
@@ -179,3 +350,20 @@ func (tp TimeParser) startOfMonth(t time.Time) time.Time {
 func (tp TimeParser) endOfMonth(t time.Time) time.Time {
 	return tp.startOfMonth(t).AddDate(0, 1, 0).Add(-1 * time.Second)
 }
+
+func (tp TimeParser) startOfQuarter(t time.Time) time.Time {
+	firstMonth := (t.Month()-1)/3*3 + 1
+	return time.Date(t.Year(), firstMonth, 1, 0, 0, 0, 0, t.Location())
+}
+
+func (tp TimeParser) endOfQuarter(t time.Time) time.Time {
+	return tp.startOfQuarter(t).AddDate(0, 3, 0).Add(-1 * time.Second)
+}
+
+func (tp TimeParser) startOfYear(t time.Time) time.Time {
+	return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+}
+
+func (tp TimeParser) endOfYear(t time.Time) time.Time {
+	return tp.startOfYear(t).AddDate(1, 0, 0).Add(-1 * time.Second)
+}
@@ -59,6 +59,82 @@ func TestParseFormat(t *testing.T) {
 	testInputOutput(t, ref, "2018-02-01T16:20", timeAt("2018-02-01 16:20:00 -0400 EDT"), timeAt("2018-02-01 23:59:59 -0400 EDT"))
 }
 
+func TestParseNaturalLanguage(t *testing.T) {
+	ref := timeAt("2018-03-23 17:53:30 -0400 EDT") // a friday
+	for _, tc := range []struct {
+		input string
+		start string
+		end   string
+	}{
+		{"in 3 days", "2018-03-26 00:00:00", "2018-03-26 23:59:59"},
+		{"in a week", "2018-03-30 00:00:00", "2018-03-30 23:59:59"},
+		{"in 1 month", "2018-04-23 00:00:00", "2018-04-23 23:59:59"},
+		{"in 2 hours", "2018-03-23 19:53:30", "2018-03-23 19:53:30"},
+		{"in 30 minutes", "2018-03-23 18:23:30", "2018-03-23 18:23:30"},
+		{"2 weeks ago", "2018-03-09 00:00:00", "2018-03-09 23:59:59"},
+		{"1 day ago", "2018-03-22 00:00:00", "2018-03-22 23:59:59"},
+		{"a year ago", "2017-03-23 00:00:00", "2017-03-23 23:59:59"},
+		{"quarter", "2018-01-01 00:00:00", "2018-03-31 23:59:59"},
+		{"this quarter", "2018-01-01 00:00:00", "2018-03-31 23:59:59"},
+		{"next quarter", "2018-04-01 00:00:00", "2018-06-30 23:59:59"},
+		{"last quarter", "2017-10-01 00:00:00", "2017-12-31 23:59:59"},
+		{"year", "2018-01-01 00:00:00", "2018-12-31 23:59:59"},
+		{"last year", "2017-01-01 00:00:00", "2017-12-31 23:59:59"},
+		{"next year", "2019-01-01 00:00:00", "2019-12-31 23:59:59"},
+		{"end of month", "2018-03-31 00:00:00", "2018-03-31 23:59:59"},
+		{"end of week", "2018-03-25 00:00:00", "2018-03-25 23:59:59"},
+		{"end of quarter", "2018-03-31 00:00:00", "2018-03-31 23:59:59"},
+		{"end of year", "2018-12-31 00:00:00", "2018-12-31 23:59:59"},
+		{"start of month", "2018-03-01 00:00:00", "2018-03-01 23:59:59"},
+		{"start of week", "2018-03-19 00:00:00", "2018-03-19 23:59:59"},
+		{"tomorrow 9am", "2018-03-24 09:00:00", "2018-03-24 23:59:59"},
+		{"friday 14:30", "2018-03-23 14:30:00", "2018-03-23 23:59:59"},
+		{"next monday 9:15am", "2018-03-26 09:15:00", "2018-03-26 23:59:59"},
+		{"2018-03-03 15:00", "2018-03-03 15:00:00", "2018-03-03 23:59:59"},
+		{"15:00", "2018-03-23 15:00:00", "2018-03-23 23:59:59"},
+		{"9:30PM", "2018-03-23 21:30:00", "2018-03-23 23:59:59"},
+		{"12am", "2018-03-23 00:00:00", "2018-03-23 23:59:59"},
+		{"2024-W12", "2024-03-18 00:00:00", "2024-03-24 23:59:59"},
+		{"2020-W53", "2020-12-28 00:00:00", "2021-01-03 23:59:59"},
+		{"2018-w01", "2018-01-01 00:00:00", "2018-01-07 23:59:59"},
+	} {
+		testInputOutput(t, ref, tc.input, timeAt(tc.start+" -0400 EDT"), timeAt(tc.end+" -0400 EDT"))
+	}
+}
+
+func TestParseNaturalLanguageErrors(t *testing.T) {
+	ref := timeAt("2018-03-23 17:53:30 -0400 EDT")
+	for _, input := range []string{"in some days", "3 fortnights ago", "25:00", "13pm", "end of day", "middle of month", "2019-W53", "2018-W00", "someday 9am"} {
+		_, err := TimeParser{Input: input, startTime: ref}.Parse()
+		if err == nil {
+			t.Errorf("Input '%s' should have failed to parse", input)
+		}
+	}
+}
+
+func TestParseLastMonthFromEndOfMonth(t *testing.T) {
+	ref := timeAt("2018-03-31 12:00:00 -0400 EDT")
+	testInputOutput(t, ref, "last month", timeAt("2018-02-01 00:00:00 -0400 EDT"), timeAt("2018-02-28 23:59:59 -0400 EDT"))
+	testInputOutput(t, ref, "next month", timeAt("2018-04-01 00:00:00 -0400 EDT"), timeAt("2018-04-30 23:59:59 -0400 EDT"))
+}
+
+func TestParseRange(t *testing.T) {
+	ref := timeAt("2018-03-23 17:53:30 -0400 EDT")
+	testRangeInputOutput(t, ref, "2018-03-03..2018-03-17", timeAt("2018-03-03 00:00:00 -0400 EDT"), timeAt("2018-03-17 23:59:59 -0400 EDT"))
+	testRangeInputOutput(t, ref, "last monday..yesterday", timeAt("2018-03-12 00:00:00 -0400 EDT"), timeAt("2018-03-22 23:59:59 -0400 EDT"))
+	testRangeInputOutput(t, ref, "last monday .. yesterday", timeAt("2018-03-12 00:00:00 -0400 EDT"), timeAt("2018-03-22 23:59:59 -0400 EDT"))
+	testRangeInputOutput(t, ref, "..2018-01-01", Timespan{}.EarliestTime(), timeAt("2018-01-01 23:59:59 -0400 EDT"))
+	testRangeInputOutput(t, ref, "2018-01-01..", timeAt("2018-01-01 00:00:00 -0400 EDT"), Timespan{}.LatestTime())
+	testRangeInputOutput(t, ref, "today", timeAt("2018-03-23 00:00:00 -0400 EDT"), timeAt("2018-03-23 23:59:59 -0400 EDT"))
+
+	testRangeInputOutput(t, ref, "2018-03-03 9am..2018-03-17 17:00", timeAt("2018-03-03 09:00:00 -0400 EDT"), timeAt("2018-03-17 17:00:00 -0400 EDT"))
+
+	_, err := TimeParser{Input: "2018-01-01..someday", startTime: ref}.ParseRange()
+	if err == nil {
+		t.Errorf("expected an error for an unparseable end")
+	}
+}
+
 func timeAt(rfc string) time.Time {
 	ret, _ := time.Parse("2006-01-02 15:04:05 -0700 MST", rfc)
 	return ret
@@ -77,3 +153,17 @@ func testInputOutput(t *testing.T, referenceTime time.Time, input string, expect
 		t.Errorf("Input end '%s' failed to match expected %v, was %v", input, expectedEnd, output.End)
 	}
 }
+
+func testRangeInputOutput(t *testing.T, referenceTime time.Time, input string, expectedStart, expectedEnd time.Time) {
+	tp := TimeParser{Input: input, startTime: referenceTime}
+	output, err := tp.ParseRange()
+	if err != nil {
+		t.Fatalf("Input '%s' failed with error %v", input, err)
+	}
+	if !output.Start.Equal(expectedStart) {
+		t.Errorf("Input start '%s' failed to match expected %v, was %v", input, expectedStart, output.Start)
+	}
+	if !output.End.Equal(expectedEnd) {
+		t.Errorf("Input end '%s' failed to match expected %v, was %v", input, expectedEnd, output.End)
+	}
+}
@@ -0,0 +1,371 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/josler/wdid/filter"
+	"github.com/juju/ansiterm"
+)
+
+// Start tracks time against a task, stopping anything else being tracked
+func Start(ctx context.Context, idString string) error {
+	item, err := FindOneOrPrint(ctx, idString)
+	if err != nil {
+		return err
+	}
+	if item.Kind() != Task {
+		return fmt.Errorf("can only track time against tasks, %s is a %v", item.ID(), item.KindName())
+	}
+
+	now := time.Now()
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+		running, err := store.ListRunningIntervals()
+		if err != nil {
+			return err
+		}
+		for _, interval := range running {
+			if interval.ItemID == item.ID() {
+				return fmt.Errorf("already tracking %s", item.ID())
+			}
+			interval.End = now
+			err = store.SaveInterval(interval)
+			if err != nil {
+				return err
+			}
+		}
+		return store.SaveInterval(NewInterval(item.ID(), now))
+	})
+	if err != nil {
+		return err
+	}
+	NewItemPrinter(ctx).Print(item)
+	return nil
+}
+
+// Stop stops tracking time against a task, or against anything if idString is empty
+func Stop(ctx context.Context, idString string) error {
+	store := ctx.Value("store").(Store)
+	running, err := store.ListRunningIntervals()
+	if err != nil {
+		return err
+	}
+
+	if idString != "" {
+		item, err := FindOneOrPrint(ctx, idString)
+		if err != nil {
+			return err
+		}
+		forItem := []*Interval{}
+		for _, interval := range running {
+			if interval.ItemID == item.ID() {
+				forItem = append(forItem, interval)
+			}
+		}
+		if len(forItem) == 0 {
+			return fmt.Errorf("not tracking %s", item.ID())
+		}
+		running = forItem
+	}
+	if len(running) == 0 {
+		return errors.New("not tracking anything")
+	}
+
+	now := time.Now()
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+		for _, interval := range running {
+			interval.End = now
+			err := store.SaveInterval(interval)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	items := []*Item{}
+	for _, interval := range running {
+		item, err := findExactItem(store, interval.ItemID)
+		if err == nil {
+			items = append(items, item)
+		}
+	}
+	NewItemPrinter(ctx).Print(items...)
+	return nil
+}
+
+// TimeReport sums the time tracked against the items matching filterString, by the day it
+// was tracked on and by each tag on the items. A time in the filters is when the time was
+// tracked, rather than the time of the items, and only the time tracked within it is counted.
+func TimeReport(ctx context.Context, filterString string) error {
+	report, err := timeReportFor(ctx, filterString, time.Now())
+	if err != nil {
+		return err
+	}
+	report.fPrint(os.Stdout, GetPrintFormatFromContext(ctx))
+	return nil
+}
+
+func timeReportFor(ctx context.Context, filterString string, now time.Time) (*timeReport, error) {
+	store := ctx.Value("store").(Store)
+	timespan, filters, err := timeReportFilters(store, kindsFromContext(ctx), statusesFromContext(ctx), filterString, now)
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := store.ListIntervalsBetween(timespan.Start, timespan.End)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := []string{}
+	byItem := map[string][]*Interval{}
+	for _, interval := range intervals {
+		if _, ok := byItem[interval.ItemID]; !ok {
+			itemIDs = append(itemIDs, interval.ItemID)
+		}
+		byItem[interval.ItemID] = append(byItem[interval.ItemID], interval)
+	}
+
+	report := newTimeReport(timespan, now)
+	for _, itemID := range itemIDs {
+		item, err := findExactItem(store, itemID)
+		if err == ErrNotFound {
+			continue // removed since
+		}
+		if err != nil {
+			return nil, err
+		}
+		matched, err := matchesAll(filters, item)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			report.add(item, byItem[itemID])
+		}
+	}
+	return report, nil
+}
+
+// timeReportFilters splits filterString into the time to report on, all time up to now if it
+// doesn't have one, and the filters the items must match
+func timeReportFilters(store Store, kinds Kinds, statuses Statuses, filterString string, now time.Time) (*Timespan, []filter.Filter, error) {
+	timespan, err := TimeParser{Input: filterString}.ParseRange()
+	if err == nil {
+		return timespan, []filter.Filter{}, nil
+	}
+
+	filters, err := parseFilters(store, kinds, statuses, filterString)
+	if err != nil {
+		return nil, nil, err
+	}
+	dateFilter, rest := findFirstDateFilter(filters)
+	for _, f := range rest {
+		if _, ok := f.(*DateFilter); ok {
+			return nil, nil, errors.New("time report only takes one time, with =")
+		}
+		if containsDateFilter(f) {
+			return nil, nil, errors.New("time report can't take a time inside and, or, not or a group")
+		}
+	}
+	if dateFilter == nil {
+		return NewTimespan(Timespan{}.EarliestTime(), now), rest, nil
+	}
+	return dateFilter.timespan, rest, nil
+}
+
+// containsDateFilter is true if there's a time filter anywhere inside f, where it would match the
+// time of the items rather than when time was tracked against them
+func containsDateFilter(f filter.Filter) bool {
+	var inner []filter.Filter
+	switch typed := f.(type) {
+	case *DateFilter:
+		return true
+	case *filter.And:
+		inner = typed.Filters
+	case *filter.Or:
+		inner = typed.Filters
+	case *filter.Not:
+		inner = []filter.Filter{typed.Filter}
+	case *GroupFilter:
+		inner = typed.groupFilters
+	}
+	for _, innerFilter := range inner {
+		if containsDateFilter(innerFilter) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(filters []filter.Filter, item *Item) (bool, error) {
+	for _, f := range filters {
+		ok, err := f.Match(MatchableItem{Item: item})
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// stopTracking stops any time being tracked against an item, when it's finished
+func stopTracking(store Store, itemID string, now time.Time) error {
+	running, err := store.ListRunningIntervals()
+	if err != nil {
+		return err
+	}
+	for _, interval := range running {
+		if interval.ItemID != itemID {
+			continue
+		}
+		interval.End = now
+		err = store.SaveInterval(interval)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UntaggedReportName is what time tracked against items without tags is reported under
+const UntaggedReportName = "(untagged)"
+
+type timeReport struct {
+	timespan *Timespan // only time tracked within it is counted
+	now      time.Time
+	days     map[string]time.Duration // keyed by date, as 2006-01-02
+	tags     map[string]time.Duration
+	total    time.Duration
+}
+
+func newTimeReport(timespan *Timespan, now time.Time) *timeReport {
+	return &timeReport{timespan: timespan, now: now, days: map[string]time.Duration{}, tags: map[string]time.Duration{}}
+}
+
+func (report *timeReport) add(item *Item, intervals []*Interval) {
+	var tracked time.Duration
+	for _, interval := range intervals {
+		start, end := report.clip(interval)
+		// split intervals running past midnight between the days they cover
+		for start.Before(end) {
+			nextDay := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+			until := end
+			if nextDay.Before(end) {
+				until = nextDay
+			}
+			report.days[start.Format("2006-01-02")] += until.Sub(start)
+			tracked += until.Sub(start)
+			start = until
+		}
+	}
+	if tracked == 0 {
+		return
+	}
+	report.total += tracked
+
+	if len(item.Tags()) == 0 {
+		report.tags[UntaggedReportName] += tracked
+	}
+	for _, tag := range item.Tags() {
+		report.tags[tag.Name()] += tracked
+	}
+}
+
+// clip is the part of the interval within the report's timespan, up until now if it's still running
+func (report *timeReport) clip(interval *Interval) (time.Time, time.Time) {
+	start, end := interval.Start, interval.End
+	if interval.Running() {
+		end = report.now
+	}
+	if start.Before(report.timespan.Start) {
+		start = report.timespan.Start
+	}
+	if end.After(report.timespan.End) {
+		end = report.timespan.End
+	}
+	return start, end
+}
+
+type JSONTimeReportEntry struct {
+	Name    string
+	Seconds int64
+}
+
+type JSONTimeReport struct {
+	Days         []JSONTimeReportEntry
+	Tags         []JSONTimeReportEntry
+	TotalSeconds int64
+}
+
+func (report *timeReport) fPrint(w io.Writer, printFormat PrintFormat) {
+	days := sortedDurationKeys(report.days)
+	tags := sortedDurationKeys(report.tags)
+
+	switch printFormat {
+	case TextPrintFormat:
+		for _, day := range days {
+			fmt.Fprintf(w, "day\t%s\t%d\n", day, int64(report.days[day].Seconds()))
+		}
+		for _, tag := range tags {
+			fmt.Fprintf(w, "tag\t%s\t%d\n", tag, int64(report.tags[tag].Seconds()))
+		}
+		fmt.Fprintf(w, "total\t\t%d\n", int64(report.total.Seconds()))
+	case HumanPrintFormat:
+		tw := ansiterm.NewTabWriter(w, ColMinWidth, 0, 1, ' ', 0)
+		defer tw.Flush()
+		baseColor := color.New(color.Bold)
+		baseColor.EnableColor()
+
+		fmt.Fprint(tw, baseColor.Sprintln("By day:"))
+		for _, day := range days {
+			parsed, _ := time.ParseInLocation("2006-01-02", day, time.Local)
+			fmt.Fprintf(tw, "%s\t%s\t\n", parsed.Format("Mon Jan 02 2006"), formatDuration(report.days[day]))
+		}
+		fmt.Fprint(tw, baseColor.Sprintln("\nBy tag:"))
+		for _, tag := range tags {
+			fmt.Fprintf(tw, "%s\t%s\t\n", tag, formatDuration(report.tags[tag]))
+		}
+		fmt.Fprintf(tw, "\n%s\t%s\t\n", baseColor.Sprint("Total"), formatDuration(report.total))
+	case JSONPrintFormat:
+		jsonReport := JSONTimeReport{Days: []JSONTimeReportEntry{}, Tags: []JSONTimeReportEntry{}, TotalSeconds: int64(report.total.Seconds())}
+		for _, day := range days {
+			jsonReport.Days = append(jsonReport.Days, JSONTimeReportEntry{Name: day, Seconds: int64(report.days[day].Seconds())})
+		}
+		for _, tag := range tags {
+			jsonReport.Tags = append(jsonReport.Tags, JSONTimeReportEntry{Name: tag, Seconds: int64(report.tags[tag].Seconds())})
+		}
+		buf := bytes.Buffer{}
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		err := encoder.Encode(jsonReport)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "%s", buf.String())
+	}
+}
+
+func sortedDurationKeys(durations map[string]time.Duration) []string {
+	keys := []string{}
+	for key := range durations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestStart(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "now")
+		found := mostRecentItem(store)
+
+		err := Start(ctx, found.ID())
+		assert.NilError(t, err)
+		running, _ := store.ListRunningIntervals()
+		assert.Equal(t, len(running), 1)
+		assert.Equal(t, running[0].ItemID, found.ID())
+
+		err = Start(ctx, found.ID())
+		assert.Error(t, err, "already tracking "+found.ID())
+	})
+}
+
+func TestStartStopsOthers(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		first := NewTask("first", time.Now().Add(-time.Minute))
+		store.Save(first)
+		second := NewTask("second", time.Now())
+		store.Save(second)
+
+		Start(ctx, first.ID())
+		err := Start(ctx, second.ID())
+		assert.NilError(t, err)
+
+		running, _ := store.ListRunningIntervals()
+		assert.Equal(t, len(running), 1)
+		assert.Equal(t, running[0].ItemID, second.ID())
+		intervals, _ := store.ListIntervals(first.ID())
+		assert.Assert(t, !intervals[0].Running())
+	})
+}
+
+func TestStartNote(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		AddNote(ctx, strings.NewReader("my note"), "now")
+		found := mostRecentItem(store)
+		err := Start(ctx, found.ID())
+		assert.Error(t, err, "can only track time against tasks, "+found.ID()+" is a note")
+	})
+}
+
+func TestStop(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "now")
+		found := mostRecentItem(store)
+
+		err := Stop(ctx, "")
+		assert.Error(t, err, "not tracking anything")
+
+		Start(ctx, found.ID())
+		err = Stop(ctx, found.ID())
+		assert.NilError(t, err)
+		running, _ := store.ListRunningIntervals()
+		assert.Equal(t, len(running), 0)
+
+		err = Stop(ctx, found.ID())
+		assert.Error(t, err, "not tracking "+found.ID())
+	})
+}
+
+func TestUndoStart(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "now")
+		found := mostRecentItem(store)
+
+		journaled(ctx, store, "start", func(ctx context.Context) {
+			Start(ctx, found.ID())
+		})
+		journaled(ctx, store, "stop", func(ctx context.Context) {
+			Stop(ctx, "")
+		})
+
+		err := Undo(ctx, 1)
+		assert.NilError(t, err)
+		running, _ := store.ListRunningIntervals()
+		assert.Equal(t, len(running), 1)
+
+		err = Undo(ctx, 1)
+		assert.NilError(t, err)
+		intervals, _ := store.ListIntervals(found.ID())
+		assert.Equal(t, len(intervals), 0)
+	})
+}
+
+func TestTimeReport(t *testing.T) {
+	start := time.Date(2020, 1, 1, 23, 0, 0, 0, time.Local)
+	item := NewTask("billable #client", start)
+	overnight := NewInterval(item.ID(), start)
+	overnight.End = start.Add(2 * time.Hour)
+	running := NewInterval(item.ID(), start.Add(3*time.Hour))
+
+	now := start.Add(4 * time.Hour)
+	report := newTimeReport(NewTimespan(Timespan{}.EarliestTime(), now), now)
+	report.add(item, []*Interval{overnight, running})
+	report.add(NewTask("untracked", start), []*Interval{})
+
+	assert.Equal(t, report.total, 3*time.Hour)
+	assert.DeepEqual(t, report.days, map[string]time.Duration{"2020-01-01": time.Hour, "2020-01-02": 2 * time.Hour})
+	assert.DeepEqual(t, report.tags, map[string]time.Duration{"#client": 3 * time.Hour})
+
+	report = newTimeReport(NewTimespan(start.Add(30*time.Minute), start.Add(210*time.Minute)), now)
+	report.add(item, []*Interval{overnight, running})
+	assert.Equal(t, report.total, 2*time.Hour)
+	assert.DeepEqual(t, report.days, map[string]time.Duration{"2020-01-01": 30 * time.Minute, "2020-01-02": 90 * time.Minute})
+}
+
+func TestTimeReportByWhenTracked(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)
+		old := NewTask("old #client", day.AddDate(0, -1, 0))
+		store.Save(old)
+		untagged := NewTask("untagged", day.AddDate(0, -1, 0))
+		store.Save(untagged)
+
+		yesterday := NewInterval(old.ID(), day.Add(-2*time.Hour))
+		yesterday.End = day.Add(time.Hour)
+		store.SaveInterval(yesterday)
+		store.SaveInterval(NewInterval(untagged.ID(), day.Add(11*time.Hour)))
+		now := day.Add(12 * time.Hour)
+
+		report, err := timeReportFor(ctx, "2020-01-02", now)
+		assert.NilError(t, err)
+		assert.Equal(t, report.total, 2*time.Hour)
+		assert.DeepEqual(t, report.tags, map[string]time.Duration{"#client": time.Hour, UntaggedReportName: time.Hour})
+
+		report, err = timeReportFor(ctx, "tag=#client,time=2020-01-02", now)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, report.days, map[string]time.Duration{"2020-01-02": time.Hour})
+
+		report, err = timeReportFor(ctx, "tag=#client", now)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, report.days, map[string]time.Duration{"2020-01-01": 2 * time.Hour, "2020-01-02": time.Hour})
+
+		_, err = timeReportFor(ctx, "time=2020-01-02,time!=2020-01-01", now)
+		assert.Error(t, err, "time report only takes one time, with =")
+		for _, filterString := range []string{"tag=#client or time=2020-01-02", "not time=2020-01-02", "(tag=#client or time=2020-01-02),tag=#client"} {
+			_, err = timeReportFor(ctx, filterString, now)
+			assert.Error(t, err, "time report can't take a time inside and, or, not or a group", filterString)
+		}
+	})
+}
+
+func TestFinishingStopsTracking(t *testing.T) {
+	finishes := map[string]func(ctx context.Context, id string) error{
+		"do":   Do,
+		"skip": Skip,
+		"bump": func(ctx context.Context, id string) error { return Bump(ctx, id, "tomorrow") },
+	}
+	for name, finish := range finishes {
+		t.Run(name, func(t *testing.T) {
+			contextWithStore(func(ctx context.Context, store Store) {
+				item := NewTask("tracked", time.Now())
+				store.Save(item)
+				Start(ctx, item.ID())
+
+				err := finish(ctx, item.ID())
+				assert.NilError(t, err)
+				running, _ := store.ListRunningIntervals()
+				assert.Equal(t, len(running), 0)
+			})
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+)
+
+func ListTrash(ctx context.Context) error {
+	store := ctx.Value("store").(Store)
+	items, err := store.ListTrash()
+	if err != nil {
+		return err
+	}
+	NewItemPrinter(ctx).Print(items...)
+	return nil
+}
+
+func Restore(ctx context.Context, idString string) error {
+	store := ctx.Value("store").(Store)
+	items, err := store.FindAllTrash(idString)
+	item, err := oneOrPrint(ctx, items, err)
+	if err != nil {
+		return err
+	}
+	err = store.Restore(item)
+	if err != nil {
+		return err
+	}
+	NewItemPrinter(ctx).Print(item)
+	return nil
+}
+
+// EmptyTrash permanently removes items from the trash. If olderThan is given, only items
+// trashed before then are removed.
+func EmptyTrash(ctx context.Context, olderThan string) error {
+	store := ctx.Value("store").(Store)
+	items, err := store.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	if olderThan != "" {
+		span, err := TimeParser{Input: olderThan}.Parse()
+		if err != nil {
+			return err
+		}
+		older := []*Item{}
+		for _, item := range items {
+			if item.TrashedAt().Before(span.Start) {
+				older = append(older, item)
+			}
+		}
+		items = older
+	}
+
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		store := ctx.Value("store").(Store)
+		for _, item := range items {
+			err := store.DeleteTrash(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	NewItemPrinter(ctx).Print(items...)
+	return nil
+}
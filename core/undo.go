@@ -0,0 +1,172 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/juju/ansiterm"
+)
+
+// Undo reverses the last count operations, newest first
+func Undo(ctx context.Context, count int) error {
+	store := ctx.Value("store").(Store)
+	operations, err := store.ListOperations()
+	if err != nil {
+		return err
+	}
+	if len(operations) == 0 {
+		return errors.New("nothing to undo")
+	}
+	if count > len(operations) {
+		count = len(operations)
+	}
+
+	for _, operation := range operations[:count] {
+		err = withTransaction(ctx, func(ctx context.Context) error {
+			store := ctx.Value("store").(Store)
+			for i := len(operation.Changes) - 1; i >= 0; i-- {
+				err := undoChange(store, operation.Changes[i])
+				if err != nil {
+					return err
+				}
+			}
+			return store.DeleteOperation(operation)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to undo %q: %w", operation.Name, err)
+		}
+		fPrintOperations(os.Stdout, GetPrintFormatFromContext(ctx), operation)
+	}
+	return nil
+}
+
+func undoChange(store Store, change *Change) error {
+	switch change.Type {
+	case itemChange:
+		if change.ItemBefore == nil { // added
+			item, err := findExactItem(store, change.ItemAfter.ID)
+			if err != nil {
+				return err
+			}
+			return store.Delete(item)
+		}
+		if change.ItemAfter == nil { // deleted
+			item := &Item{}
+			change.ItemBefore.applyTo(item)
+			return store.Save(item)
+		}
+		item, err := findExactItem(store, change.ItemAfter.ID)
+		if err != nil {
+			return err
+		}
+		before := *item
+		change.ItemBefore.applyTo(item)
+		err = store.Save(item)
+		if err != nil {
+			return err
+		}
+		return saveRevisions(store, &before, item)
+	case trashChange:
+		item, err := findExactTrashedItem(store, change.ItemBefore.ID)
+		if err != nil {
+			return err
+		}
+		return store.Restore(item)
+	case restoreChange:
+		item, err := findExactItem(store, change.ItemBefore.ID)
+		if err != nil {
+			return err
+		}
+		return store.Trash(item)
+	case trashDeleteChange:
+		item := &Item{}
+		change.ItemBefore.applyTo(item)
+		err := store.Save(item)
+		if err != nil {
+			return err
+		}
+		return store.Trash(item)
+	case groupChange:
+		if change.GroupBefore == nil { // added
+			group, err := store.FindGroupByName(change.GroupAfter.Name)
+			if err != nil {
+				return err
+			}
+			return store.DeleteGroup(group)
+		}
+		if change.GroupAfter == nil { // deleted
+			return store.SaveGroup(change.GroupBefore.group())
+		}
+		group, err := store.FindGroupByName(change.GroupAfter.Name)
+		if err != nil {
+			return err
+		}
+		restored := change.GroupBefore.group()
+		restored.internalID = group.internalID
+		return store.SaveGroup(restored)
+	case intervalChange:
+		if change.IntervalBefore == nil { // started
+			interval, err := findExactInterval(store, change.IntervalAfter.ItemID, time.Unix(change.IntervalAfter.Start, 0))
+			if err != nil {
+				return err
+			}
+			return store.DeleteInterval(interval)
+		}
+		if change.IntervalAfter == nil { // deleted
+			return store.SaveInterval(change.IntervalBefore.interval())
+		}
+		interval, err := findExactInterval(store, change.IntervalAfter.ItemID, time.Unix(change.IntervalAfter.Start, 0))
+		if err != nil {
+			return err
+		}
+		restored := change.IntervalBefore.interval()
+		restored.internalID = interval.internalID
+		return store.SaveInterval(restored)
+	}
+	return fmt.Errorf("unrecognized change %q", change.Type)
+}
+
+func ListOperations(ctx context.Context) error {
+	store := ctx.Value("store").(Store)
+	operations, err := store.ListOperations()
+	if err != nil {
+		return err
+	}
+	fPrintOperations(os.Stdout, GetPrintFormatFromContext(ctx), operations...)
+	return nil
+}
+
+type JSONOperation struct {
+	Name       string
+	TimeString string
+	Changes    int
+}
+
+func fPrintOperations(w io.Writer, printFormat PrintFormat, operations ...*Operation) {
+	tw := ansiterm.NewTabWriter(w, ColMinWidth, 0, 1, ' ', 0)
+	defer tw.Flush()
+
+	for _, operation := range operations {
+		switch printFormat {
+		case TextPrintFormat:
+			fmt.Fprintf(w, "%s\t%s\t%d\n", operation.At.Format(time.RFC3339), operation.Name, len(operation.Changes))
+		case HumanPrintFormat:
+			fmt.Fprintf(tw, "%s\t%s\t(%d changes)\t\n", operation.At.Format("Mon, 02 Jan 2006 15:04:05"), operation.Name, len(operation.Changes))
+		case JSONPrintFormat:
+			buf := bytes.Buffer{}
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			err := encoder.Encode(JSONOperation{Name: operation.Name, TimeString: operation.At.Format(time.RFC3339), Changes: len(operation.Changes)})
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(tw, "%s", buf.String())
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// journaled runs f the way a command is run, recording what it changes
+func journaled(ctx context.Context, store Store, name string, f func(ctx context.Context)) {
+	journal := NewJournal(store, name)
+	f(context.WithValue(ctx, "store", journal))
+	journal.Commit()
+}
+
+func TestUndoAdd(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		journaled(ctx, store, "add", func(ctx context.Context) {
+			Add(ctx, strings.NewReader("my new item #hashtag"), "now")
+		})
+		found := mostRecentItem(store)
+
+		err := Undo(ctx, 1)
+		assert.NilError(t, err)
+		_, err = store.FindAll(found.ID())
+		assert.Equal(t, err, ErrNotFound)
+	})
+}
+
+func TestUndoDoAndEdit(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		journaled(ctx, store, "edit", func(ctx context.Context) {
+			Edit(ctx, found.ID(), strings.NewReader("my edited item"), "2018-04-03")
+		})
+		journaled(ctx, store, "do", func(ctx context.Context) {
+			Do(ctx, found.ID())
+		})
+
+		err := Undo(ctx, 1)
+		assert.NilError(t, err)
+		items, _ := store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), WaitingStatus)
+		assert.Equal(t, items[0].Data(), "my edited item")
+
+		err = Undo(ctx, 1)
+		assert.NilError(t, err)
+		items, _ = store.FindAll(found.ID())
+		assert.Equal(t, items[0].Data(), "my new item")
+		assert.Equal(t, items[0].Time(), found.Time())
+
+		// undoing is recorded in the item's history
+		revisions, _ := store.ListRevisions(found.ID())
+		assert.Equal(t, revisions[len(revisions)-1].New, found.Time().Format(time.RFC3339))
+
+		err = Undo(ctx, 1)
+		assert.Error(t, err, "nothing to undo")
+	})
+}
+
+func TestUndoBump(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("my new item"), "2018-04-02")
+		found := mostRecentItem(store)
+
+		journaled(ctx, store, "bump", func(ctx context.Context) {
+			Bump(ctx, found.ID(), "2018-04-03")
+		})
+		bumped, _ := store.FindAll(found.ID())
+		nextID := bumped[0].NextID()
+
+		err := Undo(ctx, 1)
+		assert.NilError(t, err)
+		items, _ := store.FindAll(found.ID())
+		assert.Equal(t, items[0].Status(), WaitingStatus)
+		assert.Equal(t, items[0].NextID(), "")
+		_, err = store.FindAll(nextID)
+		assert.Equal(t, err, ErrNotFound)
+	})
+}
+
+func TestUndoRm(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		Add(ctx, strings.NewReader("trashed item"), "2018-04-02")
+		trashed := mostRecentItem(store)
+		Add(ctx, strings.NewReader("deleted item #hashtag"), "2018-04-03")
+		deleted := mostRecentItem(store)
+
+		journaled(ctx, store, "rm", func(ctx context.Context) {
+			Rm(ctx, trashed.ID(), false)
+		})
+		journaled(ctx, store, "rm --force", func(ctx context.Context) {
+			Rm(ctx, deleted.ID(), true)
+		})
+
+		err := Undo(ctx, 2)
+		assert.NilError(t, err)
+		items, err := store.FindAll(trashed.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, items[0].Data(), "trashed item")
+		items, err = store.FindAll(deleted.ID())
+		assert.NilError(t, err)
+		assert.Equal(t, items[0].Data(), "deleted item #hashtag")
+		assert.Equal(t, items[0].Time(), deleted.Time())
+	})
+}
+
+func TestUndoGroup(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		CreateGroup(ctx, "kept", "tag=#foo")
+		journaled(ctx, store, "group", func(ctx context.Context) {
+			CreateGroup(ctx, "my group", "tag=#foo")
+		})
+		journaled(ctx, store, "group-rm", func(ctx context.Context) {
+			DeleteGroup(ctx, "kept")
+		})
+
+		err := Undo(ctx, 2)
+		assert.NilError(t, err)
+		_, err = store.FindGroupByName("my group")
+		assert.Equal(t, err, ErrNotFound)
+		group, err := store.FindGroupByName("kept")
+		assert.NilError(t, err)
+		assert.Equal(t, group.FilterString, "tag=#foo")
+	})
+}
+
+func TestJournalSkipsRolledBackChanges(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		journaled(ctx, store, "failed", func(ctx context.Context) {
+			withTransaction(ctx, func(ctx context.Context) error {
+				ctx.Value("store").(Store).Save(NewTask("rolled back", time.Now()))
+				return errors.New("failed")
+			})
+		})
+		operations, err := store.ListOperations()
+		assert.NilError(t, err)
+		assert.Equal(t, len(operations), 0)
+	})
+}
+
+func TestJournalLimit(t *testing.T) {
+	contextWithStore(func(ctx context.Context, store Store) {
+		for i := 0; i < JournalLimit+2; i++ {
+			journaled(ctx, store, "add", func(ctx context.Context) {
+				Add(ctx, strings.NewReader("my new item"), "now")
+			})
+		}
+		operations, err := store.ListOperations()
+		assert.NilError(t, err)
+		assert.Equal(t, len(operations), JournalLimit)
+	})
+}
@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// And matches items that match every one of its filters
+type And struct {
+	Filters []Filter
+}
+
+func NewAnd(filters ...Filter) *And {
+	return &And{Filters: filters}
+}
+
+func (and *And) Match(i Matchable) (bool, error) {
+	for _, filter := range and.Filters {
+		ok, err := filter.Match(i)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (and *And) String() string {
+	return joinFilters(and.Filters, " and ")
+}
+
+// Or matches items that match any one of its filters
+type Or struct {
+	Filters []Filter
+}
+
+func NewOr(filters ...Filter) *Or {
+	return &Or{Filters: filters}
+}
+
+func (or *Or) Match(i Matchable) (bool, error) {
+	for _, filter := range or.Filters {
+		ok, err := filter.Match(i)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (or *Or) String() string {
+	return joinFilters(or.Filters, " or ")
+}
+
+// Not matches items that don't match its filter
+type Not struct {
+	Filter Filter
+}
+
+func NewNot(filter Filter) *Not {
+	return &Not{Filter: filter}
+}
+
+func (not *Not) Match(i Matchable) (bool, error) {
+	ok, err := not.Filter.Match(i)
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
+func (not *Not) String() string {
+	return fmt.Sprintf("not %v", not.Filter)
+}
+
+func joinFilters(filters []Filter, separator string) string {
+	strs := []string{}
+	for _, filter := range filters {
+		strs = append(strs, fmt.Sprint(filter))
+	}
+	return "(" + strings.Join(strs, separator) + ")"
+}
@@ -5,10 +5,21 @@ type Filter interface {
 }
 
 type Matchable interface {
+	ID() string
 	Data() string
 	Status() string
 	Datetime() int64
 	Kind() int64
+	CustomKind() string // empty for the built in kinds
+	Tags() []string
+	Connections() []string
+	Meta() map[string]string // key:value fields, keyed by lowercase key
+	Due() int64              // 0 if there is no due date
+	Priority() int64         // 0 if there is no priority
+
+	CreatedAt() int64   // 0 if it wasn't recorded
+	UpdatedAt() int64   // 0 if it wasn't recorded
+	CompletedAt() int64 // 0 if it hasn't been completed
 }
 
 type FilterComparison int
@@ -18,6 +29,7 @@ const (
 	FilterNe
 	FilterGt
 	FilterLt
+	FilterLike
 )
 
 func (fc FilterComparison) String() string {
@@ -30,6 +42,8 @@ func (fc FilterComparison) String() string {
 		return ">"
 	case FilterLt:
 		return "<"
+	case FilterLike:
+		return "~"
 	}
 	return ""
 }
@@ -17,9 +17,7 @@ func contextWithStore(store core.Store) context.Context {
 }
 
 func withFreshBoltStore(boltStore *core.BoltStore, f func()) {
-	boltStore.DropBucket("StormItem")
-	boltStore.DropBucket("StormTag")
-	boltStore.DropBucket("StormGroup")
+	boltStore.DropBuckets()
 	f()
 }
 
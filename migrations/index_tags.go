@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+// IndexTags re-saves every item, so that items saved before tags were persisted
+// are added to the tag index, to the word index used by text search, to the
+// metadata index used by meta filters, and to the connection index used to find blockers
+func IndexTags(ctx context.Context) {
+	store := ctx.Value("store").(core.Store)
+
+	items, err := store.ListFilters([]filter.Filter{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = store.Transaction(func(tx core.Store) error {
+		for _, item := range items {
+			err := tx.Save(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
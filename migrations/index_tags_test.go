@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm"
+	"gotest.tools/assert"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+)
+
+func TestIndexTags(t *testing.T) {
+	// write an item the way it was saved before tags were persisted, then open it as a store
+	os.Remove("/tmp/test125.db")
+	db, err := storm.Open("/tmp/test125.db")
+	assert.NilError(t, err)
+	assert.NilError(t, db.Save(&core.StormItem{
+		ID:       "abc123",
+		Data:     "legacy item #mytag client:acme [[blocks:xyz789]]",
+		Status:   core.WaitingStatus,
+		Datetime: time.Now().Unix(),
+		Kind:     int64(core.Task),
+	}))
+	assert.NilError(t, db.Close())
+
+	// opening the store backfills the indexes, so the item is found before the migration too
+	store, err := core.NewBoltStore("/tmp/test125.db")
+	assert.NilError(t, err)
+	tagFilters := []filter.Filter{core.NewTagFilter(store, filter.FilterEq, "#mytag")}
+	items, err := store.ListFilters(tagFilters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+	assert.Equal(t, items[0].ID(), "abc123")
+	textFilters := []filter.Filter{core.NewTextFilter(filter.FilterEq, "legacy")}
+	items, err = store.ListFilters(textFilters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+	items, err = store.ListFilters([]filter.Filter{core.NewMetaFilter("client", filter.FilterEq, "acme")})
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+	items, err = store.ListFilters([]filter.Filter{core.NewConnectionFilter(parser.BlocksConnection)})
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+
+	IndexTags(contextWithStore(store))
+
+	items, err = store.ListFilters(tagFilters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+	assert.Equal(t, items[0].ID(), "abc123")
+
+	items, err = store.ListFilters(textFilters)
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+}
@@ -1,19 +1,57 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/josler/wdid/filter"
 )
 
 type ToFilterFn func(comparison filter.FilterComparison, val string) (filter.Filter, error)
 
+// ToFilterFamilyFn makes filters for a family of identifiers sharing a prefix, given the rest
+// of the identifier after the prefix
+type ToFilterFamilyFn func(name string, comparison filter.FilterComparison, val string) (filter.Filter, error)
+
 type Parser struct {
 	filterFnMap map[string]ToFilterFn
-	results     []filter.Filter
+	familyFnMap map[string]ToFilterFamilyFn
+	input       string
 	itemchan    chan lexedItem
-	err         error
+	peeked      *lexedItem // the next item, if it's been looked at already
+}
+
+// ParseError is an error in a filter string, at a position in it
+type ParseError struct {
+	Input string
+	Pos   int // the byte offset in Input
+	Err   error
+}
+
+// Error shows the position of the error, with a caret under it in the input
+func (e *ParseError) Error() string {
+	column := utf8.RuneCountInString(e.Input[:e.Pos])
+	return fmt.Sprintf("%v at position %d:\n%s\n%s^", e.Err, column+1, e.Input, strings.Repeat(" ", column))
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// errorAt is a ParseError at the start of item, unless err is already from parsing another
+// filter string, like a group's
+func (p *Parser) errorAt(item lexedItem, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+	pos := item.pos
+	if pos > len(p.input) {
+		pos = len(p.input)
+	}
+	return &ParseError{Input: p.input, Pos: pos, Err: err}
 }
 
 func (p *Parser) RegisterToFilter(identifierName string, filterFn ToFilterFn) {
@@ -23,53 +61,200 @@ func (p *Parser) RegisterToFilter(identifierName string, filterFn ToFilterFn) {
 	p.filterFnMap[identifierName] = filterFn
 }
 
+// RegisterToFilterFamily registers filters for every identifier starting with prefix, like "meta."
+func (p *Parser) RegisterToFilterFamily(prefix string, familyFn ToFilterFamilyFn) {
+	if p.familyFnMap == nil {
+		p.familyFnMap = map[string]ToFilterFamilyFn{}
+	}
+	p.familyFnMap[prefix] = familyFn
+}
+
+// findFilterFn finds the filter registered for identifier, or for the family it belongs to
+func (p *Parser) findFilterFn(identifier string) (ToFilterFn, bool) {
+	if filterFn, ok := p.filterFnMap[identifier]; ok {
+		return filterFn, true
+	}
+	for prefix, familyFn := range p.familyFnMap {
+		name := strings.TrimPrefix(identifier, prefix)
+		if name != identifier && name != "" {
+			familyFn := familyFn
+			return func(comparison filter.FilterComparison, val string) (filter.Filter, error) {
+				return familyFn(name, comparison, val)
+			}, true
+		}
+	}
+	return nil, false
+}
+
+// Parse parses filters joined by commas, "and" and "or", which can be negated with "not" and
+// grouped with parentheses, e.g. "(tag=#a or tag=#b) and not status=done". "and" binds more
+// tightly than "or", and commas join everything either side of them like a lower precedence
+// "and". The results are the filters which must all match, so filters only joined by commas
+// and "and" come back as a list, as they always have.
+//
+// Values can be written in double or single quotes, to include commas, parentheses, "and", "or"
+// or spaces at either end, with a backslash escaping the character after it.
 func (p *Parser) Parse(input string) ([]filter.Filter, error) {
 	_, p.itemchan = lex(input)
-	p.parse()
-	return p.results, p.err
+	p.input = input
+	p.peeked = nil
+	defer func() {
+		// let the lexer finish if parsing stopped early
+		for range p.itemchan {
+		}
+	}()
+
+	list, err := p.parseList(lexItemEOF)
+	if err != nil {
+		return nil, err
+	}
+	var results []filter.Filter
+	for _, f := range list {
+		if and, ok := f.(*filter.And); ok {
+			results = append(results, and.Filters...)
+		} else {
+			results = append(results, f)
+		}
+	}
+	return results, nil
+}
+
+func (p *Parser) next() lexedItem {
+	if p.peeked != nil {
+		item := *p.peeked
+		p.peeked = nil
+		return item
+	}
+	item, ok := <-p.itemchan
+	if !ok {
+		// channel closed
+		return lexedItem{typ: lexItemEOF, pos: len(p.input)}
+	}
+	return item
+}
+
+func (p *Parser) peek() lexedItem {
+	if p.peeked == nil {
+		item := p.next()
+		p.peeked = &item
+	}
+	return *p.peeked
 }
 
-func (p *Parser) parse() {
+// parseList parses filters separated by commas, up to the end item
+func (p *Parser) parseList(end lexedItemType) ([]filter.Filter, error) {
+	filters := []filter.Filter{}
 	for {
-		i, ok := <-p.itemchan
-		if !ok {
-			// channel closed
-			return
+		switch item := p.peek(); item.typ {
+		case end:
+			p.next()
+			return filters, nil
+		case lexItemComma:
+			p.next()
+			continue
+		case lexItemEOF:
+			return nil, p.errorAt(item, errors.New("failed to parse, missing )"))
+		case lexItemError:
+			return nil, p.errorAt(item, errors.New(item.val))
+		}
+
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
 		}
-		var err error
+		filters = append(filters, f)
 
-		switch i.typ {
-		case lexItemIdentifier:
-			err = p.parseIdentifier(i)
+		switch item := p.peek(); item.typ {
+		case end, lexItemComma:
+		case lexItemEOF:
+			return nil, p.errorAt(item, errors.New("failed to parse, missing )"))
+		case lexItemError:
+			return nil, p.errorAt(item, errors.New(item.val))
+		default:
+			return nil, p.errorAt(item, fmt.Errorf("failed to parse, unexpected %v", item))
 		}
+	}
+}
 
+func (p *Parser) parseOr() (filter.Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	filters := []filter.Filter{f}
+	for p.peek().typ == lexItemOr {
+		p.next()
+		f, err = p.parseAnd()
 		if err != nil {
-			p.err = err
-			return
+			return nil, err
 		}
+		filters = append(filters, f)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
 	}
+	return filter.NewOr(filters...), nil
 }
 
-func (p *Parser) parseIdentifier(identifier lexedItem) error {
-	trimmedIdentifier := strings.Trim(identifier.val, " ")
-	filterFn, ok := p.filterFnMap[trimmedIdentifier]
-	if !ok {
-		return fmt.Errorf("failed to parse, unrecognized filter: %q", identifier.val)
+func (p *Parser) parseAnd() (filter.Filter, error) {
+	f, err := p.parseNot()
+	if err != nil {
+		return nil, err
 	}
-	comparison, ok := <-p.itemchan // drain the comparison
-	if !ok {
-		return fmt.Errorf("failed to parse %q, missing comparison", identifier.val)
+	filters := []filter.Filter{f}
+	for p.peek().typ == lexItemAnd {
+		p.next()
+		f, err = p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
 	}
-	valueItem, ok := <-p.itemchan // next is the valueItem
-	if !ok || valueItem.typ != lexItemString {
-		return fmt.Errorf("failed to parse %q, missing value", identifier.val)
+	if len(filters) == 1 {
+		return filters[0], nil
 	}
+	return filter.NewAnd(filters...), nil
+}
+
+func (p *Parser) parseNot() (filter.Filter, error) {
+	item := p.next()
+	switch item.typ {
+	case lexItemNot:
+		f, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return filter.NewNot(f), nil
+	case lexItemLeftParen:
+		filters, err := p.parseList(lexItemRightParen)
+		if err != nil {
+			return nil, err
+		}
+		switch len(filters) {
+		case 0:
+			return nil, p.errorAt(item, errors.New("failed to parse, empty ()"))
+		case 1:
+			return filters[0], nil
+		}
+		return filter.NewAnd(filters...), nil
+	case lexItemIdentifier:
+		return p.parseIdentifier(item)
+	case lexItemError:
+		return nil, p.errorAt(item, errors.New(item.val))
+	default:
+		return nil, p.errorAt(item, fmt.Errorf("failed to parse, expected a filter, not %v", item))
+	}
+}
 
-	if p.results == nil {
-		p.results = []filter.Filter{}
+func (p *Parser) parseIdentifier(identifier lexedItem) (filter.Filter, error) {
+	trimmedIdentifier := strings.Trim(identifier.val, " ")
+	filterFn, ok := p.findFilterFn(trimmedIdentifier)
+	if !ok {
+		return nil, p.errorAt(identifier, fmt.Errorf("failed to parse, unrecognized filter: %q", trimmedIdentifier))
 	}
 
 	var filterComparison filter.FilterComparison
+	comparison := p.next()
 	switch comparison.typ {
 	case lexItemEq:
 		filterComparison = filter.FilterEq
@@ -79,13 +264,27 @@ func (p *Parser) parseIdentifier(identifier lexedItem) error {
 		filterComparison = filter.FilterGt
 	case lexItemLt:
 		filterComparison = filter.FilterLt
+	case lexItemLike:
+		filterComparison = filter.FilterLike
+	default:
+		return nil, p.errorAt(comparison, fmt.Errorf("failed to parse %q, missing comparison", trimmedIdentifier))
+	}
+
+	valueItem := p.next() // next is the valueItem
+	if valueItem.typ == lexItemError {
+		return nil, p.errorAt(valueItem, errors.New(valueItem.val))
+	}
+	if valueItem.typ != lexItemString {
+		return nil, p.errorAt(valueItem, fmt.Errorf("failed to parse %q, missing value", trimmedIdentifier))
 	}
 
-	trimmedValue := strings.Trim(valueItem.val, " ")
-	result, err := filterFn(filterComparison, trimmedValue)
+	value := valueItem.val
+	if !valueItem.quoted {
+		value = strings.Trim(value, " ")
+	}
+	result, err := filterFn(filterComparison, value)
 	if err != nil {
-		return err
+		return nil, p.errorAt(valueItem, err)
 	}
-	p.results = append(p.results, result)
-	return nil
+	return result, nil
 }
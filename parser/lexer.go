@@ -3,12 +3,15 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
 type lexedItem struct {
-	typ lexedItemType
-	val string
+	typ    lexedItemType
+	val    string
+	pos    int  // where the item starts in the input
+	quoted bool // for values written in quotes, which are kept exactly as written
 }
 
 type lexedItemType int
@@ -20,9 +23,15 @@ const (
 	lexItemNe
 	lexItemGt
 	lexItemLt
+	lexItemLike
 	lexItemString
 	lexItemIdentifier
 	lexItemComma
+	lexItemLeftParen
+	lexItemRightParen
+	lexItemAnd
+	lexItemOr
+	lexItemNot
 )
 
 const EOF rune = 0
@@ -30,7 +39,18 @@ const EqualSign string = "="
 const NotEqualSign string = "!="
 const GtSign string = ">"
 const LtSign string = "<"
+const LikeSign string = "~"
 const Comma string = ","
+const DoubleQuote string = `"`
+const SingleQuote string = "'"
+const Backslash string = `\`
+const LeftParen string = "("
+const RightParen string = ")"
+
+// keywords combining filters, matched case insensitively
+const AndKeyword string = "and"
+const OrKeyword string = "or"
+const NotKeyword string = "not"
 
 func (i lexedItem) String() string {
 	switch i.typ {
@@ -50,6 +70,7 @@ type lexer struct {
 	start int
 	pos   int
 	width int
+	depth int // how many parentheses are open
 	items chan lexedItem
 }
 
@@ -70,10 +91,16 @@ func (l *lexer) run() {
 }
 
 func (l *lexer) emit(t lexedItemType) {
-	l.items <- lexedItem{t, l.input[l.start:l.pos]}
+	l.items <- lexedItem{typ: t, val: l.input[l.start:l.pos], pos: l.start}
 	l.start = l.pos
 }
 
+// errorf emits an error at pos and stops lexing
+func (l *lexer) errorf(pos int, format string, args ...interface{}) stateFn {
+	l.items <- lexedItem{typ: lexItemError, val: fmt.Sprintf(format, args...), pos: pos}
+	return nil
+}
+
 func (l *lexer) next() (r rune) {
 	if l.pos >= len(l.input) {
 		l.width = 0
@@ -84,7 +111,79 @@ func (l *lexer) next() (r rune) {
 	return r
 }
 
+// skipSpace moves past any whitespace, without emitting it
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) {
+		r, width := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += width
+	}
+	l.start = l.pos
+}
+
+// keywordAt is the length of keyword if the input at pos is that keyword, as a whole word
+func (l *lexer) keywordAt(pos int, keyword string) int {
+	if len(l.input)-pos < len(keyword) || !strings.EqualFold(l.input[pos:pos+len(keyword)], keyword) {
+		return 0
+	}
+	rest := l.input[pos+len(keyword):]
+	if rest != "" && !strings.HasPrefix(rest, LeftParen) {
+		r, _ := utf8.DecodeRuneInString(rest)
+		if !unicode.IsSpace(r) {
+			return 0
+		}
+	}
+	return len(keyword)
+}
+
+// emitKeyword emits the keyword at pos if there is one there
+func (l *lexer) emitKeyword(keyword string, t lexedItemType) bool {
+	n := l.keywordAt(l.pos, keyword)
+	if n == 0 {
+		return false
+	}
+	l.pos += n
+	l.emit(t)
+	return true
+}
+
+// joinsAt is true if the input at pos is whitespace followed by "and" or "or", which ends a value
+func (l *lexer) joinsAt(pos int) bool {
+	r, width := utf8.DecodeRuneInString(l.input[pos:])
+	if !unicode.IsSpace(r) {
+		return false
+	}
+	for pos += width; pos < len(l.input); pos += width {
+		r, width = utf8.DecodeRuneInString(l.input[pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+	}
+	return l.keywordAt(pos, AndKeyword) > 0 || l.keywordAt(pos, OrKeyword) > 0
+}
+
+// lexIdentifier looks for the start of a filter, which may be negated or grouped in parentheses
 func lexIdentifier(l *lexer) stateFn {
+	l.skipSpace()
+	switch {
+	case l.pos >= len(l.input):
+		l.emit(lexItemEOF)
+		return nil
+	case strings.HasPrefix(l.input[l.pos:], LeftParen):
+		l.pos += len(LeftParen)
+		l.depth++
+		l.emit(lexItemLeftParen)
+		return lexIdentifier
+	case strings.HasPrefix(l.input[l.pos:], RightParen):
+		return lexRightParen
+	case strings.HasPrefix(l.input[l.pos:], Comma):
+		return lexComma
+	case l.emitKeyword(NotKeyword, lexItemNot):
+		return lexIdentifier
+	}
+
 	for {
 		if strings.HasPrefix(l.input[l.pos:], EqualSign) {
 			if l.pos > l.start {
@@ -106,6 +205,11 @@ func lexIdentifier(l *lexer) stateFn {
 				l.emit(lexItemIdentifier)
 			}
 			return lexLt
+		} else if strings.HasPrefix(l.input[l.pos:], LikeSign) {
+			if l.pos > l.start {
+				l.emit(lexItemIdentifier)
+			}
+			return lexLike
 		}
 
 		if l.next() == EOF {
@@ -144,7 +248,20 @@ func lexLt(l *lexer) stateFn {
 	return lexString
 }
 
+func lexLike(l *lexer) stateFn {
+	l.pos += len(LikeSign)
+	l.emit(lexItemLike)
+	return lexString
+}
+
+// lexString reads a value, which runs until a comma, the end of the parentheses it's in, or
+// an "and" or "or" after a space, unless it's in quotes
 func lexString(l *lexer) stateFn {
+	rest := strings.TrimLeftFunc(l.input[l.pos:], unicode.IsSpace)
+	if strings.HasPrefix(rest, DoubleQuote) || strings.HasPrefix(rest, SingleQuote) {
+		l.skipSpace()
+		return lexQuoted
+	}
 	for {
 		if strings.HasPrefix(l.input[l.pos:], Comma) {
 			if l.pos > l.start {
@@ -152,6 +269,18 @@ func lexString(l *lexer) stateFn {
 			}
 			return lexComma
 		}
+		if l.depth > 0 && strings.HasPrefix(l.input[l.pos:], RightParen) {
+			if l.pos > l.start {
+				l.emit(lexItemString)
+			}
+			return lexRightParen
+		}
+		if l.pos < len(l.input) && l.joinsAt(l.pos) {
+			if l.pos > l.start {
+				l.emit(lexItemString)
+			}
+			return lexJoin
+		}
 		if l.next() == EOF {
 			break
 		}
@@ -164,8 +293,65 @@ func lexString(l *lexer) stateFn {
 	return nil
 }
 
+// lexQuoted reads a value in double or single quotes, where a backslash escapes the character
+// after it
+func lexQuoted(l *lexer) stateFn {
+	quote := l.next()
+	value := strings.Builder{}
+	for {
+		r := l.next()
+		switch {
+		case r == EOF && l.width == 0:
+			return l.errorf(l.start, "failed to parse, missing closing %c", quote)
+		case r == quote:
+			l.items <- lexedItem{typ: lexItemString, val: value.String(), pos: l.start, quoted: true}
+			l.start = l.pos
+			return lexJoin
+		case string(r) == Backslash:
+			escaped := l.next()
+			if escaped == EOF && l.width == 0 {
+				return l.errorf(l.pos-1, "failed to parse, nothing to escape")
+			}
+			switch escaped {
+			case 'n':
+				value.WriteRune('\n')
+			case 't':
+				value.WriteRune('\t')
+			default:
+				value.WriteRune(escaped)
+			}
+		default:
+			value.WriteRune(r)
+		}
+	}
+}
+
 func lexComma(l *lexer) stateFn {
 	l.pos += len(Comma)
 	l.emit(lexItemComma)
 	return lexIdentifier // now looking for another identifier
 }
+
+func lexRightParen(l *lexer) stateFn {
+	l.pos += len(RightParen)
+	l.depth--
+	l.emit(lexItemRightParen)
+	return lexJoin
+}
+
+// lexJoin looks for what joins a filter or a group to the next one
+func lexJoin(l *lexer) stateFn {
+	l.skipSpace()
+	switch {
+	case l.pos >= len(l.input):
+		l.emit(lexItemEOF)
+		return nil
+	case strings.HasPrefix(l.input[l.pos:], RightParen):
+		return lexRightParen
+	case strings.HasPrefix(l.input[l.pos:], Comma):
+		return lexComma
+	case l.emitKeyword(AndKeyword, lexItemAnd), l.emitKeyword(OrKeyword, lexItemOr):
+		return lexIdentifier
+	}
+	return l.errorf(l.pos, "failed to parse, expected and, or or a comma")
+}
@@ -42,6 +42,18 @@ func TestLexerNotEqual(t *testing.T) {
 	assertLexedItemTypeValue(t, lexItems[6], lexItemString, "#hashtag")
 }
 
+func TestLexerLike(t *testing.T) {
+	_, itemchan := lex("text~release notes,tag=#hashtag")
+	lexItems := drainLexedItems(itemchan)
+	if len(lexItems) != 8 {
+		t.Errorf("failed to lex correct number of items")
+	}
+	assertLexedItemTypeValue(t, lexItems[0], lexItemIdentifier, "text")
+	assertLexedItemTypeValue(t, lexItems[1], lexItemLike, "~")
+	assertLexedItemTypeValue(t, lexItems[2], lexItemString, "release notes")
+	assertLexedItemTypeValue(t, lexItems[3], lexItemComma, ",")
+}
+
 func TestLexerSpaces(t *testing.T) {
 	_, itemchan := lex("tag=my tag")
 	lexItems := drainLexedItems(itemchan)
@@ -75,6 +87,67 @@ func TestLexerMissingValueComma(t *testing.T) {
 	}
 }
 
+func TestLexerGrouping(t *testing.T) {
+	_, itemchan := lex("(tag=#a OR tag=#b) and not status=done")
+	lexItems := drainLexedItems(itemchan)
+	if len(lexItems) != 15 {
+		t.Fatalf("failed to lex correct number of items, got %v", lexItems)
+	}
+	assertLexedItemTypeValue(t, lexItems[0], lexItemLeftParen, "(")
+	assertLexedItemTypeValue(t, lexItems[3], lexItemString, "#a")
+	assertLexedItemTypeValue(t, lexItems[4], lexItemOr, "OR")
+	assertLexedItemTypeValue(t, lexItems[7], lexItemString, "#b")
+	assertLexedItemTypeValue(t, lexItems[8], lexItemRightParen, ")")
+	assertLexedItemTypeValue(t, lexItems[9], lexItemAnd, "and")
+	assertLexedItemTypeValue(t, lexItems[10], lexItemNot, "not")
+	assertLexedItemTypeValue(t, lexItems[11], lexItemIdentifier, "status")
+	assertLexedItemTypeValue(t, lexItems[13], lexItemString, "done")
+}
+
+func TestLexerKeywordsInValues(t *testing.T) {
+	_, itemchan := lex("text~orange notes,tag=#band)")
+	lexItems := drainLexedItems(itemchan)
+	if len(lexItems) != 8 {
+		t.Fatalf("failed to lex correct number of items, got %v", lexItems)
+	}
+	assertLexedItemTypeValue(t, lexItems[2], lexItemString, "orange notes")
+	assertLexedItemTypeValue(t, lexItems[6], lexItemString, "#band)")
+}
+
+func TestLexerMissingJoin(t *testing.T) {
+	_, itemchan := lex("(tag=#a) tag=#b")
+	lexItems := drainLexedItems(itemchan)
+	last := lexItems[len(lexItems)-1]
+	if last.typ != lexItemError {
+		t.Errorf("failed to correctly lex, expected an error, got %v", last)
+	}
+}
+
+func TestLexerQuoted(t *testing.T) {
+	_, itemchan := lex(`text~" a, b = c ",group='it\'s (mine)' or text="\\"`)
+	lexItems := drainLexedItems(itemchan)
+	if len(lexItems) != 12 {
+		t.Fatalf("failed to lex correct number of items, got %v", lexItems)
+	}
+	assertLexedItemTypeValue(t, lexItems[2], lexItemString, " a, b = c ")
+	assertLexedItemTypeValue(t, lexItems[3], lexItemComma, ",")
+	assertLexedItemTypeValue(t, lexItems[6], lexItemString, "it's (mine)")
+	assertLexedItemTypeValue(t, lexItems[7], lexItemOr, "or")
+	assertLexedItemTypeValue(t, lexItems[10], lexItemString, `\`)
+	if !lexItems[2].quoted || lexItems[2].pos != 5 {
+		t.Errorf("failed to correctly lex, expected a quoted value at 5, got %v", lexItems[2])
+	}
+}
+
+func TestLexerUnterminatedQuote(t *testing.T) {
+	_, itemchan := lex(`text="release notes`)
+	lexItems := drainLexedItems(itemchan)
+	last := lexItems[len(lexItems)-1]
+	if last.typ != lexItemError || last.pos != 5 {
+		t.Errorf("failed to correctly lex, expected an error at 5, got %v", last)
+	}
+}
+
 func drainLexedItems(itemchan chan lexedItem) []lexedItem {
 	lexItems := []lexedItem{}
 	for i := range itemchan {
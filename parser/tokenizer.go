@@ -1,15 +1,26 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
 )
 
 type TokenResult struct {
 	Tags        []string
 	Connections []string
+	Checklist   []ChecklistItem
+	Metadata    map[string]string // inline key:value fields, keyed by lowercase key
 	Raw         string
 }
 
+// ChecklistItem is a markdown checklist line, "- [ ] text" or "- [x] text"
+type ChecklistItem struct {
+	Text    string
+	Checked bool
+}
+
 type Tokenizer struct {
 }
 
@@ -17,10 +28,27 @@ func (t *Tokenizer) Tokenize(text string) (*TokenResult, error) {
 	return &TokenResult{
 		Tags:        t.getTags(text),
 		Connections: t.getConnections(text),
+		Checklist:   t.getChecklist(text),
+		Metadata:    t.getMetadata(text),
 		Raw:         text,
 	}, nil
 }
 
+const (
+	BlockedByConnection = "blocked-by" // the item is blocked by the connected item
+	BlocksConnection    = "blocks"     // the item blocks the connected item
+)
+
+// SplitConnection splits a typed connection, "<type>:<id>", into its type and id.
+// The type is empty for untyped connections.
+func SplitConnection(connection string) (string, string) {
+	split := strings.SplitN(connection, ":", 2)
+	if len(split) == 1 {
+		return "", connection
+	}
+	return split[0], split[1]
+}
+
 func (t *Tokenizer) getConnections(text string) []string {
 	re := regexp.MustCompile(`\[\[([^\s\[\]]+)\]\]`)
 	found := re.FindAllStringSubmatch(text, -1)
@@ -56,3 +84,99 @@ func (t *Tokenizer) getTags(text string) []string {
 
 	return result
 }
+
+var checklistExp = regexp.MustCompile(`(?m)^([ \t]*[-*+][ \t]+\[)([ xX])(\][ \t]+)(.*)$`)
+
+func (t *Tokenizer) getChecklist(text string) []ChecklistItem {
+	checklist := []ChecklistItem{}
+	for _, found := range checklistExp.FindAllStringSubmatch(text, -1) {
+		checklist = append(checklist, ChecklistItem{Text: strings.TrimSpace(found[4]), Checked: found[2] != " "})
+	}
+	return checklist
+}
+
+// metadataExp matches key:value fields. Keys start with a letter, so times and ratios aren't
+// mistaken for them, and values can't start with a slash or colon, so links and "std::" aren't.
+var metadataExp = regexp.MustCompile(`(^|\s)([A-Za-z][\w-]*):([^\s/:]\S*)`)
+
+// reservedMetadataKeys are inline syntax with meanings of their own, or the schemes of links
+// written without a slash
+var reservedMetadataKeys = map[string]bool{
+	"due": true, "recur": true,
+	"mailto": true, "tel": true, "sms": true, "urn": true, "magnet": true, "data": true,
+}
+
+func (t *Tokenizer) getMetadata(text string) map[string]string {
+	metadata := map[string]string{}
+	for _, found := range metadataExp.FindAllStringSubmatch(text, -1) {
+		key := strings.ToLower(found[2])
+		value := strings.TrimRight(found[3], ".,;:!?)")
+		if reservedMetadataKeys[key] || value == "" {
+			continue
+		}
+		if _, ok := metadata[key]; !ok {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// ToggleChecklistItem ticks the nth checklist item in text, counting from 1, or unticks it if it's already ticked
+func (t *Tokenizer) ToggleChecklistItem(text string, n int) (string, error) {
+	found := checklistExp.FindAllStringSubmatchIndex(text, -1)
+	if n < 1 || n > len(found) {
+		return text, fmt.Errorf("checklist item %d not found, there are %d", n, len(found))
+	}
+	box := found[n-1][4:6]
+	mark := "x"
+	if text[box[0]:box[1]] != " " {
+		mark = " "
+	}
+	return text[:box[0]] + mark + text[box[1]:], nil
+}
+
+var dueExp = regexp.MustCompile(`(^|\s)due:(\S+)`)
+
+// Due finds inline "due:<time>" syntax, returning the time given and the text with it removed.
+// The time is empty if there is none.
+func (t *Tokenizer) Due(text string) (string, string) {
+	return t.inlineValue(dueExp, text)
+}
+
+var recurExp = regexp.MustCompile(`(^|\s)recur:(\S+)`)
+
+// Recur finds inline "recur:<rule>" syntax, returning the rule given and the text with it removed.
+// The rule is empty if there is none.
+func (t *Tokenizer) Recur(text string) (string, string) {
+	return t.inlineValue(recurExp, text)
+}
+
+// inlineValue finds the value matched by the second group of exp, returning it and the text with the match removed
+func (t *Tokenizer) inlineValue(exp *regexp.Regexp, text string) (string, string) {
+	found := exp.FindStringSubmatchIndex(text)
+	if found == nil {
+		return "", text
+	}
+	value := text[found[4]:found[5]]
+	return value, strings.TrimSpace(text[:found[0]] + text[found[1]:])
+}
+
+var priorityExp = regexp.MustCompile(`(^|\s)!([1-4])(\s|$)`)
+
+// Priority finds inline "!1" to "!4" syntax, returning the priority given and the text with it removed.
+// The priority is empty if there is none.
+func (t *Tokenizer) Priority(text string) (string, string) {
+	found := priorityExp.FindStringSubmatchIndex(text)
+	if found == nil {
+		return "", text
+	}
+	priority := text[found[4]:found[5]]
+	return priority, strings.TrimSpace(text[:found[0]] + " " + text[found[1]:])
+}
+
+// Words splits text into lowercase words, dropping any punctuation, for searching over
+func (t *Tokenizer) Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
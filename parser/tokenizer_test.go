@@ -73,3 +73,106 @@ func TestTokenizeMultiplePrefixes(t *testing.T) {
 		t.Errorf("failed to ignore double prefixes")
 	}
 }
+
+func TestWords(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	words := tokenizer.Words("Write the #release notes, for @josler's v1.2 (again)")
+	assert.DeepEqual(t, []string{"write", "the", "release", "notes", "for", "josler", "s", "v1", "2", "again"}, words)
+}
+
+func TestDue(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	due, text := tokenizer.Due("finish the report due:friday #work")
+	assert.Equal(t, "friday", due)
+	assert.Equal(t, "finish the report #work", text)
+
+	due, text = tokenizer.Due("due:2020-01-02 finish the report")
+	assert.Equal(t, "2020-01-02", due)
+	assert.Equal(t, "finish the report", text)
+
+	due, text = tokenizer.Due("nothing overdue:here")
+	assert.Equal(t, "", due)
+	assert.Equal(t, "nothing overdue:here", text)
+}
+
+func TestPriority(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	priority, text := tokenizer.Priority("fix the build !1 #work")
+	assert.Equal(t, "1", priority)
+	assert.Equal(t, "fix the build #work", text)
+
+	priority, text = tokenizer.Priority("!3 fix the build")
+	assert.Equal(t, "3", priority)
+	assert.Equal(t, "fix the build", text)
+
+	priority, text = tokenizer.Priority("nothing!1 here !5 or !12")
+	assert.Equal(t, "", priority)
+	assert.Equal(t, "nothing!1 here !5 or !12", text)
+}
+
+func TestRecur(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	rule, text := tokenizer.Recur("weekly report recur:weekly:mon #admin")
+	assert.Equal(t, "weekly:mon", rule)
+	assert.Equal(t, "weekly report #admin", text)
+
+	rule, text = tokenizer.Recur("no recurrence")
+	assert.Equal(t, "", rule)
+	assert.Equal(t, "no recurrence", text)
+}
+
+func TestChecklist(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	result, _ := tokenizer.Tokenize("release v2\n- [ ] tag it\n- [x] write notes\n  * [X] nested\nnot - [ ] a box")
+	assert.DeepEqual(t, result.Checklist, []ChecklistItem{
+		{Text: "tag it", Checked: false},
+		{Text: "write notes", Checked: true},
+		{Text: "nested", Checked: true},
+	})
+}
+
+func TestToggleChecklistItem(t *testing.T) {
+	tokenizer := &Tokenizer{}
+	text, err := tokenizer.ToggleChecklistItem("release v2\n- [ ] tag it\n- [x] write notes", 1)
+	assert.NilError(t, err)
+	assert.Equal(t, "release v2\n- [x] tag it\n- [x] write notes", text)
+
+	text, err = tokenizer.ToggleChecklistItem(text, 2)
+	assert.NilError(t, err)
+	assert.Equal(t, "release v2\n- [x] tag it\n- [ ] write notes", text)
+
+	_, err = tokenizer.ToggleChecklistItem(text, 3)
+	assert.Error(t, err, "checklist item 3 not found, there are 2")
+}
+
+func TestSplitConnection(t *testing.T) {
+	connectionType, id := SplitConnection("blocked-by:abc123")
+	assert.Equal(t, connectionType, BlockedByConnection)
+	assert.Equal(t, id, "abc123")
+
+	connectionType, id = SplitConnection("abc123")
+	assert.Equal(t, connectionType, "")
+	assert.Equal(t, id, "abc123")
+}
+
+func TestMetadata(t *testing.T) {
+	result := getResult("ticket:ENG-123 fix login, estimate:2h Client:acme. see https://josler.io at 10:30 [[blocks:abc123]] ticket:ENG-456")
+	assert.DeepEqual(t, result.Metadata, map[string]string{
+		"ticket":   "ENG-123",
+		"estimate": "2h",
+		"client":   "acme",
+	})
+
+	result = getResult("no metadata: here")
+	assert.DeepEqual(t, result.Metadata, map[string]string{})
+
+	for _, text := range []string{
+		"ratio 3:1 at 10:30 or 9:15pm",
+		"see https://josler.io, mailto:me@josler.io or tel:+15551234",
+		"use std::vector",
+		"due:friday recur:weekly",
+	} {
+		result = getResult(text)
+		assert.Equal(t, len(result.Metadata), 0, text)
+	}
+}
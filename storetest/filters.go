@@ -0,0 +1,420 @@
+package storetest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+func filtersTests() map[string]storeTest {
+	return map[string]storeTest{
+		"listFilters":           listFilters,
+		"listFiltersNe":         listFiltersNe,
+		"listFiltersStatusOr":   listFiltersStatusOr,
+		"listFiltersGroup":      listFiltersGroup,
+		"listFiltersGroupNe":    listFiltersGroupNe,
+		"listFiltersTagUpdated": listFiltersTagUpdated,
+		"listFiltersTagAndDate": listFiltersTagAndDate,
+		"listFiltersTagDeleted": listFiltersTagDeleted,
+		"listFiltersDue":        listFiltersDue,
+		"listFiltersPriority":   listFiltersPriority,
+		"saveRecurrence":        saveRecurrence,
+		"listFiltersTimestamps": listFiltersTimestamps,
+		"listFiltersCustomKind": listFiltersCustomKind,
+		"listFiltersComposite":  listFiltersComposite,
+	}
+}
+
+func setupTagAndItems(store core.Store) {
+	tag := core.NewTag("#mytag")
+	store.SaveTag(tag)
+
+	item := core.NewTask("my item", time.Now())
+	store.Save(item)
+	doneItem := core.NewTask("#mytag done", time.Now())
+	doneItem.Do()
+	store.Save(doneItem)
+	skippedItem := core.NewTask("#mytag skipped", time.Now())
+	skippedItem.Skip()
+	store.Save(skippedItem)
+}
+
+func listFilters(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	filters := []filter.Filter{
+		core.NewStatusFilter(filter.FilterEq, "skipped"),
+		core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+	}
+	items, _ := store.ListFilters(filters)
+
+	if len(items) != 1 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "#mytag skipped" {
+		t.Errorf("data not matching")
+	}
+}
+
+func listFiltersNe(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	filters := []filter.Filter{
+		core.NewTagFilter(store, filter.FilterNe, "#mytag"),
+	}
+	items, _ := store.ListFilters(filters)
+
+	if len(items) != 1 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "my item" {
+		t.Errorf("data not matching")
+	}
+}
+
+func listFiltersStatusOr(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	filters := []filter.Filter{
+		core.NewStatusFilter(filter.FilterEq, "skipped", "done"),
+	}
+	items, _ := store.ListFilters(filters)
+
+	if len(items) != 2 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "#mytag done" {
+		t.Errorf("data not matching")
+	}
+
+	if items[1].Data() != "#mytag skipped" {
+		t.Errorf("data not matching")
+	}
+}
+
+func listFiltersGroup(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	filters := []filter.Filter{
+		core.NewGroupFilter(filter.FilterEq, "name", []filter.Filter{
+			core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+			core.NewStatusFilter(filter.FilterNe, "done"),
+		}),
+	}
+
+	items, _ := store.ListFilters(filters)
+	if len(items) != 1 {
+		t.Fatalf("wrong items found %v", items)
+	}
+
+	if items[0].Data() != "#mytag skipped" {
+		t.Errorf("data not matching")
+	}
+}
+
+func listFiltersGroupNe(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	filters := []filter.Filter{
+		core.NewGroupFilter(filter.FilterNe, "name", []filter.Filter{
+			core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+		}),
+	}
+
+	items, _ := store.ListFilters(filters)
+	if len(items) != 1 {
+		t.Fatalf("wrong items found %v", items)
+	}
+
+	if items[0].Data() != "my item" {
+		t.Errorf("data not matching")
+	}
+}
+
+func listFiltersTagUpdated(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+
+	items, _ := store.ListFilters([]filter.Filter{core.NewTagFilter(store, filter.FilterEq, "#mytag")})
+	if len(items) != 2 {
+		t.Fatalf("wrong items found %v", items)
+	}
+
+	// remove the tag from one, and add it to another
+	ctx := contextWithStore(store)
+	err := core.Edit(ctx, items[0].ID(), strings.NewReader("no longer tagged"), "")
+	if err != nil {
+		t.Fatalf("failed to edit %v", err)
+	}
+	untagged, _ := store.ListFilters([]filter.Filter{core.NewTagFilter(store, filter.FilterNe, "#mytag")})
+	for _, item := range untagged {
+		if item.Data() == "my item" {
+			err = core.Edit(ctx, item.ID(), strings.NewReader("my item #mytag #other"), "")
+			if err != nil {
+				t.Fatalf("failed to edit %v", err)
+			}
+		}
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTagFilter(store, filter.FilterEq, "#mytag")})
+	if len(items) != 2 {
+		t.Fatalf("wrong items found %v", items)
+	}
+	if items[0].Data() != "my item #mytag #other" || items[1].Data() != "#mytag skipped" {
+		t.Errorf("data not matching")
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{
+		core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+		core.NewTagFilter(store, filter.FilterEq, "#other"),
+	})
+	if len(items) != 1 || items[0].Data() != "my item #mytag #other" {
+		t.Errorf("wrong items found %v", items)
+	}
+	if len(items) == 1 && len(items[0].Tags()) != 2 {
+		t.Errorf("tags not loaded with item %v", items[0].Tags())
+	}
+}
+
+func listFiltersTagAndDate(t *testing.T, store core.Store) {
+	now := time.Now()
+	store.Save(core.NewTask("old #mytag", now.Add(-48*time.Hour)))
+	store.Save(core.NewTask("new #mytag", now.Add(-1*time.Minute)))
+	store.Save(core.NewTask("new untagged", now.Add(-1*time.Minute)))
+
+	items, _ := store.ListFilters([]filter.Filter{
+		core.NewDateFilter(filter.FilterEq, core.NewTimespan(now.Add(-1*time.Hour), now)),
+		core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+	})
+	if len(items) != 1 || items[0].Data() != "new #mytag" {
+		t.Errorf("wrong items found %v", items)
+	}
+}
+
+func listFiltersTagDeleted(t *testing.T, store core.Store) {
+	item := core.NewTask("deleted #mytag", time.Now())
+	store.Save(item)
+	store.Delete(item)
+
+	items, err := store.ListFilters([]filter.Filter{core.NewTagFilter(store, filter.FilterEq, "#mytag")})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("deleted item found by tag %v", items)
+	}
+}
+
+func listFiltersDue(t *testing.T, store core.Store) {
+	soon := core.NewTask("due soon", time.Now())
+	soon.SetDue(time.Now().Add(time.Hour))
+	store.Save(soon)
+	later := core.NewTask("due later", time.Now())
+	later.SetDue(time.Now().Add(48 * time.Hour))
+	store.Save(later)
+	store.Save(core.NewTask("never due", time.Now()))
+
+	items, err := store.ListFilters([]filter.Filter{
+		core.NewDueFilter(filter.FilterLt, &core.Timespan{Start: core.Timespan{}.EarliestTime(), End: time.Now().Add(24 * time.Hour)}),
+	})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 1 || items[0].Data() != "due soon" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{
+		core.NewDueFilter(filter.FilterGt, &core.Timespan{Start: time.Now(), End: core.Timespan{}.LatestTime()}),
+	})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	nextDay := core.NewTimespan(time.Now().Add(24*time.Hour), time.Now().Add(72*time.Hour))
+	items, _ = store.ListFilters([]filter.Filter{core.NewDueFilter(filter.FilterEq, nextDay)})
+	if len(items) != 1 || items[0].Data() != "due later" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// != matches items not due in the range, including those never due
+	items, _ = store.ListFilters([]filter.Filter{core.NewDueFilter(filter.FilterNe, nextDay)})
+	if len(items) != 2 || items[0].Data() != "due soon" || items[1].Data() != "never due" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	found, _ := store.FindAll(later.ID())
+	if len(found) != 1 || found[0].Due().Unix() != later.Due().Unix() {
+		t.Errorf("due not saved, got %v", found)
+	}
+}
+
+func listFiltersPriority(t *testing.T, store core.Store) {
+	for _, priority := range []core.Priority{core.HighPriority, core.MediumPriority, core.LowestPriority, core.NoPriority} {
+		item := core.NewTask(fmt.Sprintf("priority %v", priority), time.Now())
+		item.SetPriority(priority)
+		store.Save(item)
+	}
+
+	items, err := store.ListFilters([]filter.Filter{core.NewPriorityFilter(filter.FilterLt, core.LowPriority)})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 2 || items[0].Priority() != core.HighPriority || items[1].Priority() != core.MediumPriority {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewPriorityFilter(filter.FilterGt, core.HighPriority)})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewPriorityFilter(filter.FilterEq, core.NoPriority)})
+	if len(items) != 1 || items[0].Data() != "priority " {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewPriorityFilter(filter.FilterNe, core.HighPriority)})
+	if len(items) != 3 {
+		t.Errorf("wrong items found %v", items)
+	}
+}
+
+func saveRecurrence(t *testing.T, store core.Store) {
+	item := core.NewTask("weekly report", time.Now())
+	recurrence, _ := core.ParseRecurrence("weekly mon,thu")
+	item.SetRecurrence(recurrence)
+	store.Save(item)
+
+	found, err := store.FindAll(item.ID())
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if found[0].Recurrence() != "weekly mon,thu" {
+		t.Errorf("recurrence not saved, got %q", found[0].Recurrence())
+	}
+
+	item.SetRecurrence(nil)
+	store.Save(item)
+	found, _ = store.FindAll(item.ID())
+	if found[0].Recurrence() != "" {
+		t.Errorf("recurrence not cleared, got %q", found[0].Recurrence())
+	}
+}
+
+func listFiltersTimestamps(t *testing.T, store core.Store) {
+	done := core.NewTask("done", time.Now().Add(-48*time.Hour))
+	done.Do()
+	store.Save(done)
+	store.Save(core.NewTask("waiting", time.Now().Add(-48*time.Hour)))
+
+	today := &core.Timespan{Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour)}
+	items, err := store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CompletedTimestamp, filter.FilterEq, today)})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 1 || items[0].Data() != "done" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CreatedTimestamp, filter.FilterEq, today)})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// ranges, open at either end
+	sinceYesterday := core.NewTimespan(time.Now().Add(-24*time.Hour), core.Timespan{}.LatestTime())
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CompletedTimestamp, filter.FilterEq, sinceYesterday)})
+	if len(items) != 1 || items[0].Data() != "done" {
+		t.Errorf("wrong items found %v", items)
+	}
+	untilYesterday := core.NewTimespan(core.Timespan{}.EarliestTime(), time.Now().Add(-24*time.Hour))
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CreatedTimestamp, filter.FilterEq, untilYesterday)})
+	if len(items) != 0 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// != matches items outside the range, and those without the timestamp
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CompletedTimestamp, filter.FilterNe, today)})
+	if len(items) != 1 || items[0].Data() != "waiting" {
+		t.Errorf("wrong items found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CompletedTimestamp, filter.FilterNe, untilYesterday)})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewTimestampFilter(core.CreatedTimestamp, filter.FilterNe, today)})
+	if len(items) != 0 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	found, _ := store.FindAll(done.ID())
+	if len(found) != 1 || found[0].CompletedAt().Unix() != done.CompletedAt().Unix() || found[0].UpdatedAt().Unix() != done.UpdatedAt().Unix() {
+		t.Errorf("timestamps not saved, got %v", found)
+	}
+}
+
+func listFiltersCustomKind(t *testing.T, store core.Store) {
+	kinds := testKinds()
+	meeting := core.NewTask("standup", time.Now())
+	meeting.SetKindDefinition(kinds["meeting"])
+	store.Save(meeting)
+	idea := core.NewTask("a new app", time.Now())
+	idea.SetKindDefinition(kinds["idea"])
+	store.Save(idea)
+	store.Save(core.NewTask("task", time.Now()))
+
+	items, err := store.ListFilters([]filter.Filter{core.NewKindFilterFor(filter.FilterEq, kinds["meeting"])})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 1 || items[0].KindName() != "meeting" || items[0].Status() != core.WaitingStatus {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewKindFilter(filter.FilterEq, core.Task)})
+	if len(items) != 1 || items[0].Data() != "task" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewKindFilterFor(filter.FilterNe, kinds["idea"])})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	found, _ := store.FindAll(idea.ID())
+	if len(found) != 1 || found[0].KindName() != "idea" || found[0].Status() != core.NoStatus {
+		t.Errorf("kind not saved, got %v", found)
+	}
+}
+
+func listFiltersComposite(t *testing.T, store core.Store) {
+	setupTagAndItems(store)
+	store.Save(core.NewTask("waiting #other", time.Now()))
+
+	mytag := core.NewTagFilter(store, filter.FilterEq, "#mytag")
+	other := core.NewTagFilter(store, filter.FilterEq, "#other")
+	waiting := core.NewStatusFilter(filter.FilterEq, core.WaitingStatus)
+
+	items, err := store.ListFilters([]filter.Filter{filter.NewOr(mytag, other)})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{filter.NewOr(mytag, other), filter.NewNot(waiting)})
+	if len(items) != 2 || items[0].Tags()[0].Name() != "#mytag" || items[1].Tags()[0].Name() != "#mytag" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// filters that can't all be pushed down to the store are still matched
+	items, _ = store.ListFilters([]filter.Filter{filter.NewOr(filter.NewAnd(other, waiting), core.NewTextFilter(filter.FilterLike, "my item"))})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+}
@@ -0,0 +1,83 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/josler/wdid/core"
+)
+
+func groupsTests() map[string]storeTest {
+	return map[string]storeTest{
+		"saveGroup":              saveGroup,
+		"saveGroupDuplicateName": saveGroupDuplicateName,
+		"deleteGroup":            deleteGroup,
+		"findGroupNotFound":      findGroupNotFound,
+		"listGroups":             listGroups,
+	}
+}
+
+func saveGroup(t *testing.T, store core.Store) {
+	group := core.NewGroup("group name", "tag=#foo,status!=done")
+	err := store.SaveGroup(group)
+	if err != nil {
+		t.Fatalf("failed to save group %v", err)
+	}
+
+	group, err = store.FindGroupByName("group name")
+	if err != nil {
+		t.Errorf("failed to find group")
+	}
+	if group.FilterString != "tag=#foo,status!=done" {
+		t.Error("failed to load group filterstring")
+	}
+}
+
+func saveGroupDuplicateName(t *testing.T, store core.Store) {
+	err := store.SaveGroup(core.NewGroup("group name", "tag=#foo"))
+	if err != nil {
+		t.Fatalf("failed to save group %v", err)
+	}
+	err = store.SaveGroup(core.NewGroup("group name", "tag=#bar"))
+	if err != core.ErrAlreadyExists {
+		t.Errorf("expected already exists error, got %v", err)
+	}
+}
+
+func deleteGroup(t *testing.T, store core.Store) {
+	group := core.NewGroup("group name", "tag=#foo,status!=done")
+	err := store.SaveGroup(group)
+	if err != nil {
+		t.Fatalf("failed to save group %v", err)
+	}
+
+	err = store.DeleteGroup(group)
+	if err != nil {
+		t.Fatalf("failed to delete group %v", err)
+	}
+}
+
+func findGroupNotFound(t *testing.T, store core.Store) {
+	_, err := store.FindGroupByName("missing")
+	if err != core.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func listGroups(t *testing.T, store core.Store) {
+	group := core.NewGroup("group name", "tag=#foo,status!=done")
+	err := store.SaveGroup(group)
+	if err != nil {
+		t.Fatalf("failed to save group %v", err)
+	}
+
+	groups, err := store.ListGroups()
+	if err != nil {
+		t.Fatalf("failed to list groups %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("didn't load groups")
+	}
+	if groups[0].Name != "group name" {
+		t.Errorf("did not load correct group")
+	}
+}
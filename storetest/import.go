@@ -0,0 +1,151 @@
+package storetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/josler/wdid/core"
+)
+
+func importTests() map[string]storeTest {
+	return map[string]storeTest{
+		"import":                            testImport,
+		"importExistingSameInternalID":      testImportExistingSameInternalID,
+		"importExistingDifferentInternalID": testImportExistingDifferentInternalID,
+		"importExistingNoInternalID":        testImportExistingNoInternalID,
+		"importKind":                        testImportKind,
+		"importCustomKind":                  testImportCustomKind,
+		"importBadField":                    testImportBadField,
+	}
+}
+
+func testImport(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	f := bytes.NewBufferString("s36i4z	recEJFQBuZsArxrJI	done	<-4agi3u	some change	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, f)
+	found, err := core.FindOneOrPrint(ctx, "s36i4z")
+	if err != nil || found.Data() != "some change" {
+		t.Errorf("item not saved")
+	}
+	if found.Kind() != core.Task {
+		t.Errorf("did not save item as Task without explicit kind")
+	}
+}
+
+func testImportExistingSameInternalID(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	f := bytes.NewBufferString("s36i4c	recEJFQBuZsArxrJI	done	<-4agi3u	some change	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, f)
+	g := bytes.NewBufferString("s36i4c	recEJFQBuZsArxrJI	done	<-4agi3u	more detail	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, g)
+
+	found, err := core.FindOneOrPrint(ctx, "s36i4c")
+	if err != nil {
+		t.Fatalf("item not saved")
+	}
+	if found.Data() != "more detail" {
+		t.Errorf("item has wrong data %s", found.Data())
+	}
+}
+
+func testImportExistingDifferentInternalID(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	f := bytes.NewBufferString("s36i4b	recEJFQBuZsArxrJI	done	<-4agi3u	some change	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, f)
+	g := bytes.NewBufferString("s36i4b	zzzzzz	done	<-4agi3u	more detail	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, g)
+
+	found, err := core.FindOneOrPrint(ctx, "s36i4b")
+	if err != nil || found.Data() != "more detail" {
+		t.Errorf("item not saved")
+	}
+}
+
+func testImportExistingNoInternalID(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	f := bytes.NewBufferString("s36i4b	recEJFQBuZsArxrJI	done	<-4agi3u	some change	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, f)
+	g := bytes.NewBufferString("s36i4b	 	done	<-4agi3u	more detail	2018-04-11T08:15:00-04:00")
+	core.ReadToStore(ctx, g)
+
+	found, err := core.FindOneOrPrint(ctx, "s36i4b")
+	if err != nil || found.Data() != "more detail" {
+		t.Errorf("item not saved")
+	}
+}
+
+func testImportKind(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	f := bytes.NewBufferString("s36i4z	recEJFQBuZsArxrJI	done	<-4agi3u	some change	2018-04-11T08:15:00-04:00	note")
+	core.ReadToStore(ctx, f)
+	found, err := core.FindOneOrPrint(ctx, "s36i4z")
+	if err != nil || found.Kind() != core.Note {
+		t.Errorf("item not saved as Note")
+	}
+}
+
+func testImportCustomKind(t *testing.T, store core.Store) {
+	ctx := context.WithValue(contextWithStore(store), "kinds", testKinds())
+	f := bytes.NewBufferString("s36i4y	recEJFQBuZsArxrJI	none	 	an idea	2018-04-11T08:15:00-04:00	idea")
+	err := core.ReadToStore(ctx, f)
+	found, findErr := core.FindOneOrPrint(ctx, "s36i4y")
+	if err != nil || findErr != nil || found.KindName() != "idea" || found.Kind() != core.Note {
+		t.Errorf("item not saved as idea")
+	}
+
+	g := bytes.NewBufferString("s36i4x	recEJFQBuZsArxrJI	none	 	a decision	2018-04-11T08:15:00-04:00	decision")
+	err = core.ReadToStore(ctx, g)
+	found, findErr = core.FindOneOrPrint(ctx, "s36i4x")
+	if err != nil || findErr != nil || found.KindName() != "decision" || found.Kind() != core.Note {
+		t.Errorf("item not saved as a note with its removed kind, %v", err)
+	}
+
+	h := bytes.NewBufferString("s36i4v	recEJFQBuZsArxrJI	waiting	 	a bug	2018-04-11T08:15:00-04:00	bug")
+	err = core.ReadToStore(ctx, h)
+	found, findErr = core.FindOneOrPrint(ctx, "s36i4v")
+	if err != nil || findErr != nil || found.KindName() != "bug" || found.Kind() != core.Task {
+		t.Errorf("item not saved as a task with its removed kind, %v", err)
+	}
+}
+
+func testImportBadField(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	line := "s36i4w	recEJFQBuZsArxrJI	waiting	 	a task	2018-04-11T08:15:00-04:00	task	%s	%s	%s	%s	%s	%s"
+	good := []string{"2018-04-12T08:15:00-04:00", "1", "daily", "2018-04-11T08:15:00-04:00", "2018-04-11T08:15:00-04:00", ""}
+	for n, field := range []string{"due", "priority", "recurrence", "created", "updated", "completed"} {
+		fields := append([]string{}, good...)
+		fields[n] = "not a " + field
+		args := []interface{}{}
+		for _, f := range fields {
+			args = append(args, f)
+		}
+		err := core.ReadToStore(ctx, bytes.NewBufferString(fmt.Sprintf(line, args...)))
+		if err == nil || !strings.HasPrefix(err.Error(), "failed to import s36i4w: ") {
+			t.Errorf("imported bad %s, %v", field, err)
+		}
+		if _, err := store.FindAll("s36i4w"); err != core.ErrNotFound {
+			t.Errorf("saved item with bad %s", field)
+		}
+	}
+
+	err := core.ReadToStore(ctx, bytes.NewBufferString("s36i4w	recEJFQBuZsArxrJI	waiting	 	a task	yesterday"))
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to import s36i4w: ") {
+		t.Errorf("imported bad time, %v", err)
+	}
+
+	fields := []interface{}{}
+	for _, f := range good {
+		fields = append(fields, f)
+	}
+	err = core.ReadToStore(ctx, bytes.NewBufferString(fmt.Sprintf(line, fields...)+"\n\n"))
+	if err != nil {
+		t.Fatalf("failed to import, %v", err)
+	}
+	found, _ := store.FindAll("s36i4w")
+	if len(found) != 1 || found[0].Priority() != core.HighPriority || found[0].Recurrence() == "" {
+		t.Errorf("item not imported, got %v", found)
+	}
+}
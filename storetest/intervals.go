@@ -0,0 +1,95 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+)
+
+func intervalsTests() map[string]storeTest {
+	return map[string]storeTest{
+		"listIntervals":        listIntervals,
+		"listIntervalsBetween": listIntervalsBetween,
+	}
+}
+
+func listIntervals(t *testing.T, store core.Store) {
+	now := time.Now()
+	earlier := core.NewInterval("abc123", now.Add(-2*time.Hour))
+	earlier.End = now.Add(-time.Hour)
+	running := core.NewInterval("abc123", now.Add(-time.Minute))
+	other := core.NewInterval("def456", now.Add(-30*time.Minute))
+	for _, interval := range []*core.Interval{running, earlier, other} {
+		err := store.SaveInterval(interval)
+		if err != nil {
+			t.Fatalf("error %s", err)
+		}
+	}
+
+	intervals, err := store.ListIntervals("abc123")
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(intervals) != 2 || intervals[0].Start.Unix() != earlier.Start.Unix() || !intervals[1].Running() {
+		t.Errorf("wrong intervals %v", intervals)
+	}
+
+	intervals, _ = store.ListRunningIntervals()
+	if len(intervals) != 2 || intervals[0].ItemID != "def456" || intervals[1].ItemID != "abc123" {
+		t.Errorf("wrong running intervals %v", intervals)
+	}
+
+	running.End = now
+	err = store.SaveInterval(running)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	intervals, _ = store.ListRunningIntervals()
+	if len(intervals) != 1 || intervals[0].ItemID != "def456" {
+		t.Errorf("interval not stopped %v", intervals)
+	}
+
+	err = store.DeleteInterval(other)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	intervals, _ = store.ListIntervals("def456")
+	if len(intervals) != 0 {
+		t.Errorf("interval not deleted %v", intervals)
+	}
+	err = store.DeleteInterval(other)
+	if err != core.ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func listIntervalsBetween(t *testing.T, store core.Store) {
+	now := time.Now()
+	before := core.NewInterval("abc123", now.Add(-3*time.Hour))
+	before.End = now.Add(-2 * time.Hour)
+	overlapping := core.NewInterval("abc123", now.Add(-90*time.Minute))
+	overlapping.End = now.Add(-30 * time.Minute)
+	running := core.NewInterval("def456", now.Add(-2*time.Hour))
+	after := core.NewInterval("ghi789", now.Add(time.Hour))
+	after.End = now.Add(2 * time.Hour)
+	for _, interval := range []*core.Interval{before, overlapping, running, after} {
+		err := store.SaveInterval(interval)
+		if err != nil {
+			t.Fatalf("error %s", err)
+		}
+	}
+
+	intervals, err := store.ListIntervalsBetween(now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(intervals) != 2 || intervals[0].ItemID != "def456" || intervals[1].Start.Unix() != overlapping.Start.Unix() {
+		t.Errorf("wrong intervals %v", intervals)
+	}
+
+	intervals, _ = store.ListIntervalsBetween(now.Add(-2*time.Hour), now.Add(-90*time.Minute))
+	if len(intervals) != 1 || intervals[0].ItemID != "def456" {
+		t.Errorf("intervals touching the span not left out %v", intervals)
+	}
+}
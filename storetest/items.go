@@ -0,0 +1,108 @@
+package storetest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+)
+
+func itemsTests() map[string]storeTest {
+	return map[string]storeTest{
+		"saveAlreadyExists": saveAlreadyExists,
+		"saveUpdate":        saveUpdate,
+		"find":              find,
+		"findAll":           findAll,
+		"findAllNotFound":   findAllNotFound,
+		"showPartialID":     showPartialID,
+		"doDelete":          doDelete,
+	}
+}
+
+func saveAlreadyExists(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	err := store.Save(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	item.ResetInternalID()
+	err = store.Save(item)
+	if err != nil && err != core.ErrAlreadyExists {
+		t.Fatalf("error %s", err)
+	}
+}
+
+func saveUpdate(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	err := store.Save(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	item.Do()
+	err = store.Save(item)
+	if err != nil || item.Status() != core.DoneStatus {
+		t.Fatalf("error updating item")
+	}
+}
+
+func find(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	store.Save(item)
+	found, err := store.FindAll(item.ID())
+	if len(found) != 1 {
+		t.Errorf("found wrong number of items")
+	}
+	if err != nil || found[0].ID() != item.ID() {
+		t.Errorf("error item not found correctly")
+	}
+}
+
+func findAll(t *testing.T, store core.Store) {
+	item := core.NewTask("to be saved twice", time.Now())
+	store.Save(item)
+	item.ResetInternalID()
+	item.SetID(fmt.Sprintf("%s%s", item.ID()[:3], "yyy"))
+	err := store.Save(item) // save a copy
+	if err != nil {
+		t.Fatalf("error: %s", err)
+	}
+	found, err := store.FindAll(item.ID()[:2])
+	if err != nil || len(found) != 2 {
+		t.Errorf("error items not found correctly")
+	}
+}
+
+func findAllNotFound(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	store.Save(item)
+	found, err := store.FindAll("zzzzzz")
+	if err != core.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("found items when none should match")
+	}
+}
+
+func showPartialID(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	store.Save(item)
+	found, err := store.FindAll(item.ID()[:2])
+	if len(found) != 1 {
+		t.Errorf("found wrong number of items")
+	}
+	if err != nil || found[0].ID() != item.ID() {
+		t.Errorf("error item not found correctly")
+	}
+}
+
+func doDelete(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	store.Save(item)
+	store.Delete(item)
+	_, err := store.FindAll(item.ID())
+	if err != core.ErrNotFound {
+		t.Errorf("error item not found correctly")
+	}
+}
@@ -0,0 +1,281 @@
+package storetest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+func listTests() map[string]storeTest {
+	return map[string]storeTest{
+		"list":                    list,
+		"saveListNote":            saveListNote,
+		"listEmptyShouldNotError": listEmptyShouldNotError,
+		"listDate":                listDate,
+		"listDateNe":              listDateNe,
+		"listStatus":              listStatus,
+		"listSortedByTime":        listSortedByTime,
+		"listSorted":              listSorted,
+		"listLimitOffset":         listLimitOffset,
+	}
+}
+
+func list(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now().Add(-1*time.Minute))
+	err := store.Save(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	timespan := core.NewTimespan(time.Now().Add(-1*time.Hour), time.Now())
+	filters := []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan)}
+	items, _ := store.ListFilters(filters)
+	if len(items) != 1 {
+		t.Fatalf("error: no items found")
+	}
+	if items[0].ID() != item.ID() {
+		t.Errorf("error id not matching")
+	}
+	if items[0].Kind() != core.Task {
+		t.Errorf("item not saved as Task")
+	}
+}
+
+func saveListNote(t *testing.T, store core.Store) {
+	item := core.NewNote("some data", time.Now())
+	err := store.Save(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	timespan := core.NewTimespan(time.Now().Add(-1*time.Hour), time.Now())
+	filters := []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan)}
+	items, _ := store.ListFilters(filters)
+	if len(items) != 1 {
+		t.Fatalf("error: no items found")
+	}
+	if items[0].ID() != item.ID() {
+		t.Errorf("error id not matching")
+	}
+	if items[0].Kind() != core.Note {
+		t.Errorf("item not saved as Note")
+	}
+}
+
+func listEmptyShouldNotError(t *testing.T, store core.Store) {
+	// interestingly, this test doesn't fail when the database is completely empty
+	// it only fails when there has been at least one write.
+	item := core.NewTask("some data", time.Now().Add(-1*time.Minute))
+	store.Save(item)
+	timespan := core.NewTimespan(time.Now().Add(24*time.Hour), time.Now().Add(48*time.Hour))
+	filters := []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan)}
+	items, err := store.ListFilters(filters)
+	if len(items) != 0 {
+		t.Fatalf("error: items found when they shouldn't have been")
+	}
+	if err != nil {
+		t.Errorf("error returned for empty list, %v", err)
+	}
+}
+
+func listDate(t *testing.T, store core.Store) {
+	now := time.Now()
+	store.Save(core.NewTask("1", now.Add(-48*time.Hour)))
+	store.Save(core.NewTask("2", now.Add(-24*time.Hour)))
+	store.Save(core.NewTask("3", now.Add(-1*time.Minute)))
+	store.Save(core.NewTask("4", now.Add(24*time.Hour)))
+	store.Save(core.NewTask("5", now.Add(1*time.Second))) // should not pick this up as it's greater than end time
+
+	timespan := core.NewTimespan(time.Now().Add(-36*time.Hour), now)
+	filters := []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan)}
+	items, _ := store.ListFilters(filters)
+	if len(items) != 2 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "2" {
+		t.Errorf("error data not matching")
+	}
+	if items[1].Data() != "3" {
+		t.Errorf("error data not matching")
+	}
+}
+
+func listDateNe(t *testing.T, store core.Store) {
+	now := time.Now()
+	store.Save(core.NewTask("1", now.Add(-48*time.Hour)))
+	store.Save(core.NewTask("2", now.Add(-24*time.Hour)))
+	store.Save(core.NewTask("3", now.Add(-1*time.Minute)))
+
+	timespan := core.NewTimespan(now.Add(-36*time.Hour), now.Add(-12*time.Hour))
+	items, err := store.ListFilters([]filter.Filter{core.NewDateFilter(filter.FilterNe, timespan)})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 2 || items[0].Data() != "1" || items[1].Data() != "3" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// and along with a range to search over
+	within := core.NewDateFilter(filter.FilterEq, core.NewTimespan(now.Add(-72*time.Hour), now.Add(-12*time.Hour)))
+	items, _ = store.ListFilters([]filter.Filter{core.NewDateFilter(filter.FilterNe, timespan), within})
+	if len(items) != 1 || items[0].Data() != "1" {
+		t.Errorf("wrong items found %v", items)
+	}
+}
+
+func listStatus(t *testing.T, store core.Store) {
+	store.Save(core.NewTask("1", time.Now()))
+	doneItem := core.NewTask("2", time.Now())
+	doneItem.Do()
+	store.Save(doneItem)
+	skippedItem := core.NewTask("3", time.Now())
+	skippedItem.Skip()
+	store.Save(skippedItem)
+
+	timespan := core.NewTimespan(time.Now().Add(-1*time.Hour), time.Now())
+	filters := []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan), core.NewStatusFilter(filter.FilterEq, core.WaitingStatus, core.SkippedStatus)}
+	items, _ := store.ListFilters(filters)
+	if len(items) != 2 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "1" {
+		t.Errorf("error data not matching")
+	}
+	if items[1].Data() != "3" {
+		t.Errorf("error data not matching")
+	}
+
+	timespan = core.NewTimespan(time.Now().Add(-1*time.Hour), time.Now())
+	filters = []filter.Filter{core.NewDateFilter(filter.FilterEq, timespan), core.NewStatusFilter(filter.FilterEq, core.DoneStatus)}
+	items, _ = store.ListFilters(filters)
+	if len(items) != 1 {
+		t.Fatalf("error: not all items found")
+	}
+	if items[0].Data() != "2" {
+		t.Errorf("error data not matching")
+	}
+}
+
+func listSortedByTime(t *testing.T, store core.Store) {
+	now := time.Now()
+	store.Save(core.NewTask("3", now.Add(-1*time.Minute)))
+	store.Save(core.NewTask("1", now.Add(-48*time.Hour)))
+	store.Save(core.NewTask("2", now.Add(-24*time.Hour)))
+
+	items, err := store.ListFilters([]filter.Filter{})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("error: not all items found")
+	}
+	for i, item := range items {
+		if item.Data() != fmt.Sprintf("%d", i+1) {
+			t.Errorf("items not sorted by time, found %s at %d", item.Data(), i)
+		}
+	}
+}
+
+func setupSortItems(store core.Store) {
+	now := time.Now()
+	a := core.NewTask("a #zeta", now.Add(-4*time.Hour))
+	a.SetPriority(core.LowPriority)
+	store.Save(a)
+	store.Save(core.NewNote("b", now.Add(-3*time.Hour)))
+	c := core.NewTask("c #alpha", now.Add(-2*time.Hour))
+	c.SetPriority(core.HighPriority)
+	c.Do()
+	store.Save(c)
+	d := core.NewTask("d #beta", now.Add(-1*time.Hour))
+	d.SetPriority(core.MediumPriority)
+	d.Skip()
+	store.Save(d)
+}
+
+// itemOrder is the first word of each item, in order
+func itemOrder(items []*core.Item) string {
+	order := ""
+	for _, item := range items {
+		order += strings.Fields(item.Data())[0]
+	}
+	return order
+}
+
+func listSorted(t *testing.T, store core.Store) {
+	setupSortItems(store)
+
+	for _, tc := range []struct {
+		sorts []filter.Filter
+		want  string
+	}{
+		{[]filter.Filter{core.NewSortFilter(core.TimeSort, false)}, "abcd"},
+		{[]filter.Filter{core.NewSortFilter(core.TimeSort, true)}, "dcba"},
+		{[]filter.Filter{core.NewSortFilter(core.StatusSort, false)}, "acdb"},
+		{[]filter.Filter{core.NewSortFilter(core.KindSort, false)}, "bacd"},
+		{[]filter.Filter{core.NewSortFilter(core.KindSort, false), core.NewSortFilter(core.TimeSort, true)}, "bdca"},
+		{[]filter.Filter{core.NewSortFilter(core.PrioritySort, false)}, "cdab"},
+		{[]filter.Filter{core.NewSortFilter(core.PrioritySort, true)}, "badc"},
+		{[]filter.Filter{core.NewSortFilter(core.TagSort, false)}, "cdab"},
+		{[]filter.Filter{core.NewSortFilter(core.TagSort, true)}, "badc"},
+	} {
+		items, err := store.ListFilters(tc.sorts)
+		if err != nil {
+			t.Fatalf("error %s", err)
+		}
+		if itemOrder(items) != tc.want {
+			t.Errorf("sorting by %v, wanted %s, got %s", tc.sorts, tc.want, itemOrder(items))
+		}
+	}
+
+	// sorting applies along with other filters
+	notDone := core.NewStatusFilter(filter.FilterNe, core.DoneStatus)
+	items, _ := store.ListFilters([]filter.Filter{core.NewSortFilter(core.PrioritySort, false), notDone})
+	if itemOrder(items) != "dab" {
+		t.Errorf("wrong items found %v", items)
+	}
+}
+
+func listLimitOffset(t *testing.T, store core.Store) {
+	setupSortItems(store)
+	now := time.Now()
+	lastDay := core.NewDateFilter(filter.FilterEq, core.NewTimespan(now.Add(-24*time.Hour), now))
+	notDone := core.NewStatusFilter(filter.FilterNe, core.DoneStatus)
+
+	for _, tc := range []struct {
+		filters []filter.Filter
+		want    string
+	}{
+		{[]filter.Filter{core.NewLimitFilter(2)}, "ab"},
+		{[]filter.Filter{core.NewOffsetFilter(1), core.NewLimitFilter(2)}, "bc"},
+		{[]filter.Filter{core.NewOffsetFilter(3)}, "d"},
+		{[]filter.Filter{core.NewOffsetFilter(10)}, ""},
+		{[]filter.Filter{core.NewSortFilter(core.TimeSort, true), core.NewLimitFilter(2), core.NewOffsetFilter(1)}, "cb"},
+		{[]filter.Filter{lastDay, core.NewSortFilter(core.TimeSort, true), core.NewLimitFilter(1)}, "d"},
+		{[]filter.Filter{core.NewSortFilter(core.PrioritySort, false), core.NewLimitFilter(2)}, "cd"},
+		{[]filter.Filter{notDone, core.NewSortFilter(core.TimeSort, true), core.NewLimitFilter(2)}, "db"},
+		{[]filter.Filter{notDone, core.NewOffsetFilter(1), core.NewLimitFilter(1)}, "b"},
+		// the last limit given wins
+		{[]filter.Filter{core.NewLimitFilter(1), core.NewLimitFilter(3)}, "abc"},
+	} {
+		items, err := store.ListFilters(tc.filters)
+		if err != nil {
+			t.Fatalf("error %s", err)
+		}
+		if itemOrder(items) != tc.want {
+			t.Errorf("listing %v, wanted %s, got %s", tc.filters, tc.want, itemOrder(items))
+		}
+	}
+
+	// a group brings its own sort and limit, unless they're given outside it
+	group := core.NewGroupFilter(filter.FilterEq, "latest", []filter.Filter{notDone, core.NewSortFilter(core.TimeSort, true), core.NewLimitFilter(1)})
+	items, _ := store.ListFilters([]filter.Filter{group})
+	if itemOrder(items) != "d" {
+		t.Errorf("wrong items found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{group, core.NewLimitFilter(5)})
+	if itemOrder(items) != "dba" {
+		t.Errorf("wrong items found %v", items)
+	}
+}
@@ -0,0 +1,99 @@
+package storetest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+	"github.com/josler/wdid/parser"
+)
+
+func metaTests() map[string]storeTest {
+	return map[string]storeTest{
+		"listFiltersMeta":       listFiltersMeta,
+		"listFiltersConnection": listFiltersConnection,
+	}
+}
+
+func listFiltersMeta(t *testing.T, store core.Store) {
+	store.Save(core.NewTask("fix login ticket:ENG-123 estimate:2h", time.Now()))
+	store.Save(core.NewTask("write docs ticket:ENG-456 estimate:30m", time.Now()))
+	store.Save(core.NewTask("plan week estimate:3", time.Now()))
+	store.Save(core.NewTask("no metadata", time.Now()))
+
+	items, err := store.ListFilters([]filter.Filter{core.NewMetaFilter("ticket", filter.FilterEq, "ENG-123")})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 1 || items[0].Data() != "fix login ticket:ENG-123 estimate:2h" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewMetaFilter("ticket", filter.FilterNe, "ENG-123")})
+	if len(items) != 3 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// durations only compare with durations, and numbers with numbers
+	items, _ = store.ListFilters([]filter.Filter{core.NewMetaFilter("estimate", filter.FilterGt, "1h")})
+	if len(items) != 1 || items[0].Data() != "fix login ticket:ENG-123 estimate:2h" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewMetaFilter("estimate", filter.FilterLt, "5")})
+	if len(items) != 1 || items[0].Data() != "plan week estimate:3" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// editing the data updates the index
+	ctx := contextWithStore(store)
+	edited := items[0].ID()
+	err = core.Edit(ctx, edited, strings.NewReader("plan week ticket:ENG-123"), "")
+	if err != nil {
+		t.Fatalf("failed to edit %v", err)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewMetaFilter("ticket", filter.FilterEq, "ENG-123")})
+	if len(items) != 2 {
+		t.Errorf("wrong items found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewMetaFilter("estimate", filter.FilterLt, "5")})
+	if len(items) != 0 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	found, _ := store.FindAll(edited)
+	if len(found) != 1 || found[0].Meta()["ticket"] != "ENG-123" {
+		t.Errorf("metadata not found, got %v", found)
+	}
+}
+
+func listFiltersConnection(t *testing.T, store core.Store) {
+	blocking := core.NewTask("first [[blocks:abc123]]", time.Now())
+	store.Save(blocking)
+	store.Save(core.NewTask("this blocks nothing", time.Now()))
+	store.Save(core.NewTask("second [[blocked-by:abc123]]", time.Now()))
+
+	items, err := store.ListFilters([]filter.Filter{core.NewConnectionFilter(parser.BlocksConnection)})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 1 || items[0].ID() != blocking.ID() {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	// editing the data updates the index
+	err = core.Edit(contextWithStore(store), blocking.ID(), strings.NewReader("first"), "")
+	if err != nil {
+		t.Fatalf("failed to edit %v", err)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewConnectionFilter(parser.BlocksConnection)})
+	if len(items) != 0 {
+		t.Errorf("wrong items found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewConnectionFilter(parser.BlockedByConnection)})
+	if len(items) != 1 || items[0].Data() != "second [[blocked-by:abc123]]" {
+		t.Errorf("wrong items found %v", items)
+	}
+}
@@ -0,0 +1,87 @@
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+func revisionsTests() map[string]storeTest {
+	return map[string]storeTest{
+		"listRevisions":  listRevisions,
+		"listOperations": listOperations,
+	}
+}
+
+func listRevisions(t *testing.T, store core.Store) {
+	now := time.Unix(time.Now().Unix(), 0)
+	store.SaveRevision(core.NewRevision("abc123", now, "status", "waiting", "done"))
+	store.SaveRevision(core.NewRevision("xyz123", now, "data", "old", "new"))
+	store.SaveRevision(core.NewRevision("abc123", now.Add(-time.Hour), "data", "first", "second"))
+	store.SaveRevision(core.NewRevision("abc123", now, "kind", "task", "note"))
+
+	revisions, err := store.ListRevisions("abc123")
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("wrong revisions found %v", revisions)
+	}
+	if revisions[0].Field != "data" || revisions[0].Old != "first" || revisions[0].New != "second" {
+		t.Errorf("wrong first revision %v", revisions[0])
+	}
+	if revisions[1].Field != "status" || revisions[2].Field != "kind" {
+		t.Errorf("revisions at the same time not in saved order %v", revisions)
+	}
+	if !revisions[1].At.Equal(now) {
+		t.Errorf("wrong time %v", revisions[1].At)
+	}
+
+	revisions, err = store.ListRevisions("missing")
+	if err != nil || len(revisions) != 0 {
+		t.Errorf("wrong revisions found %v %v", revisions, err)
+	}
+}
+
+func listOperations(t *testing.T, store core.Store) {
+	ctx := contextWithStore(store)
+	journal := core.NewJournal(store, "add first")
+	core.Add(context.WithValue(ctx, "store", journal), strings.NewReader("first"), "now")
+	journal.Commit()
+	journal = core.NewJournal(store, "add second")
+	core.Add(context.WithValue(ctx, "store", journal), strings.NewReader("second"), "now")
+	journal.Commit()
+
+	operations, err := store.ListOperations()
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(operations) != 2 || operations[0].Name != "add second" || operations[1].Name != "add first" {
+		t.Fatalf("wrong operations %v", operations)
+	}
+	if len(operations[0].Changes) != 1 || operations[0].At.IsZero() {
+		t.Errorf("operation not saved with its changes %v", operations[0])
+	}
+
+	err = store.DeleteOperation(operations[0])
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	operations, _ = store.ListOperations()
+	if len(operations) != 1 || operations[0].Name != "add first" {
+		t.Errorf("wrong operations %v", operations)
+	}
+
+	err = core.Undo(ctx, 1)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	items, _ := store.ListFilters([]filter.Filter{})
+	if len(items) != 1 || items[0].Data() != "second" {
+		t.Errorf("operation not undone %v", items)
+	}
+}
@@ -0,0 +1,57 @@
+// Package storetest is a conformance suite for core.Store implementations. Every backend
+// should pass it, so that they all behave the same as the original BoltStore.
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/josler/wdid/config"
+	"github.com/josler/wdid/core"
+)
+
+type storeTest func(t *testing.T, store core.Store)
+
+// Run runs every conformance test as a subtest. newStore is called once per subtest
+// and must return an empty store.
+func Run(t *testing.T, newStore func() core.Store) {
+	for name, subTest := range tests() {
+		t.Run(name, func(t *testing.T) {
+			subTest(t, newStore())
+		})
+	}
+}
+
+// subtests for the store, gathered from each area's file
+func tests() map[string]storeTest {
+	all := map[string]storeTest{}
+	for _, area := range []map[string]storeTest{
+		itemsTests(),
+		listTests(),
+		filtersTests(),
+		textTests(),
+		metaTests(),
+		trashTests(),
+		revisionsTests(),
+		intervalsTests(),
+		tagsTests(),
+		groupsTests(),
+		transactionTests(),
+		importTests(),
+	} {
+		for name, subTest := range area {
+			all[name] = subTest
+		}
+	}
+	return all
+}
+
+func contextWithStore(store core.Store) context.Context {
+	ctx := context.Background()
+	return context.WithValue(ctx, "store", store)
+}
+
+func testKinds() core.Kinds {
+	kinds, _ := core.NewKinds([]config.ConfigKind{{Name: "meeting", Status: true}, {Name: "idea"}})
+	return kinds
+}
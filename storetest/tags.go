@@ -0,0 +1,86 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/josler/wdid/core"
+)
+
+func tagsTests() map[string]storeTest {
+	return map[string]storeTest{
+		"saveTag":                          saveTag,
+		"saveTagDuplicateReusesInternalID": saveTagDuplicateReusesInternalID,
+		"findTag":                          findTag,
+		"findTagNotFound":                  findTagNotFound,
+		"listTags":                         listTags,
+	}
+}
+
+func saveTag(t *testing.T, store core.Store) {
+	tag := core.NewTag("mytag")
+	err := store.SaveTag(tag)
+	if err != nil {
+		t.Errorf("failed to save tag")
+	}
+	tag = core.NewTag("mytag")
+	err = store.SaveTag(tag)
+	if err != nil {
+		t.Errorf("failed to save tag")
+	}
+}
+
+func saveTagDuplicateReusesInternalID(t *testing.T, store core.Store) {
+	tag := core.NewTag("mytag")
+	err := store.SaveTag(tag)
+	if err != nil {
+		t.Fatalf("failed to save tag")
+	}
+	duplicate := core.NewTag("mytag")
+	err = store.SaveTag(duplicate)
+	if err != nil {
+		t.Fatalf("failed to save duplicate tag")
+	}
+	if tag.InternalID() == "" || duplicate.InternalID() != tag.InternalID() {
+		t.Errorf("duplicate tag did not reuse internal id, %q vs %q", duplicate.InternalID(), tag.InternalID())
+	}
+
+	found, err := store.ListTags()
+	if err != nil || len(found) != 1 {
+		t.Errorf("duplicate tag saved twice")
+	}
+}
+
+func findTag(t *testing.T, store core.Store) {
+	tag := core.NewTag("mytag")
+	err := store.SaveTag(tag)
+	if err != nil {
+		t.Errorf("failed to save tag")
+	}
+	found, err := store.FindTag("mytag")
+	if err != nil || found == nil || found.Name() != "mytag" {
+		t.Errorf("failed to find tag")
+	}
+}
+
+func findTagNotFound(t *testing.T, store core.Store) {
+	_, err := store.FindTag("missing")
+	if err != core.ErrNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func listTags(t *testing.T, store core.Store) {
+	tagone := core.NewTag("one")
+	store.SaveTag(tagone)
+	tagtwo := core.NewTag("two")
+	store.SaveTag(tagtwo)
+
+	found, err := store.ListTags()
+	if err != nil || len(found) != 2 {
+		t.Errorf("failed to list tags")
+	}
+
+	if found[0].Name() != tagone.Name() || found[1].Name() != tagtwo.Name() {
+		t.Errorf("failed to list tags in order")
+	}
+}
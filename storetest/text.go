@@ -0,0 +1,99 @@
+package storetest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+func textTests() map[string]storeTest {
+	return map[string]storeTest{
+		"listFiltersText":        listFiltersText,
+		"listFiltersTextUpdated": listFiltersTextUpdated,
+		"listFiltersTextDeleted": listFiltersTextDeleted,
+	}
+}
+
+func setupTextItems(store core.Store) {
+	store.Save(core.NewTask("write the release notes for v2", time.Now().Add(-2*time.Hour)))
+	store.Save(core.NewTask("notes on the #db migration", time.Now().Add(-time.Hour)))
+	store.Save(core.NewTask("Release: more notes", time.Now()))
+}
+
+func listFiltersText(t *testing.T, store core.Store) {
+	setupTextItems(store)
+
+	items, err := store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "notes")})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "notes release")})
+	if len(items) != 2 || items[0].Data() != "write the release notes for v2" || items[1].Data() != "Release: more notes" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterLike, `"release notes"`)})
+	if len(items) != 1 || items[0].Data() != "write the release notes for v2" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterNe, "release")})
+	if len(items) != 1 || items[0].Data() != "notes on the #db migration" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{
+		core.NewTextFilter(filter.FilterEq, "notes"),
+		core.NewTagFilter(store, filter.FilterEq, "#db"),
+		core.NewDateFilter(filter.FilterEq, &core.Timespan{Start: time.Now().Add(-90 * time.Minute), End: time.Now()}),
+	})
+	if len(items) != 1 || items[0].Data() != "notes on the #db migration" {
+		t.Errorf("wrong items found %v", items)
+	}
+
+	items, _ = store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "missing")})
+	if len(items) != 0 {
+		t.Errorf("wrong items found %v", items)
+	}
+}
+
+func listFiltersTextUpdated(t *testing.T, store core.Store) {
+	item := core.NewTask("the migration plan", time.Now())
+	store.Save(item)
+
+	ctx := contextWithStore(store)
+	err := core.Edit(ctx, item.ID(), strings.NewReader("the rollout plan"), "")
+	if err != nil {
+		t.Fatalf("failed to edit %v", err)
+	}
+
+	items, _ := store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "migration")})
+	if len(items) != 0 {
+		t.Errorf("old text still found %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "rollout")})
+	if len(items) != 1 {
+		t.Errorf("new text not found %v", items)
+	}
+}
+
+func listFiltersTextDeleted(t *testing.T, store core.Store) {
+	item := core.NewTask("deleted migration", time.Now())
+	store.Save(item)
+	store.Delete(item)
+
+	items, err := store.ListFilters([]filter.Filter{core.NewTextFilter(filter.FilterEq, "migration")})
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("deleted item found by text %v", items)
+	}
+}
@@ -0,0 +1,123 @@
+package storetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+)
+
+func transactionTests() map[string]storeTest {
+	return map[string]storeTest{
+		"openClose":            openClose,
+		"transactionCommits":   transactionCommits,
+		"transactionRollsBack": transactionRollsBack,
+		"transactionNested":    transactionNested,
+	}
+}
+
+func openClose(t *testing.T, store core.Store) {
+	err := store.Open()
+	if err != nil {
+		t.Fatalf("failed to open store %v", err)
+	}
+	// opening twice should keep using the same handle
+	err = store.Open()
+	if err != nil {
+		t.Fatalf("failed to open store twice %v", err)
+	}
+
+	item := core.NewTask("some data", time.Now())
+	err = store.Save(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	found, err := store.FindAll(item.ID())
+	if err != nil || len(found) != 1 {
+		t.Errorf("error item not found on open store")
+	}
+
+	err = store.Close()
+	if err != nil {
+		t.Errorf("failed to close store %v", err)
+	}
+}
+
+func transactionCommits(t *testing.T, store core.Store) {
+	item := core.NewTask("some data #mytag", time.Now())
+	err := store.Transaction(func(tx core.Store) error {
+		err := tx.SaveTag(core.NewTag("#mytag"))
+		if err != nil {
+			return err
+		}
+		return tx.Save(item)
+	})
+	if err != nil {
+		t.Fatalf("transaction failed %v", err)
+	}
+
+	found, err := store.FindAll(item.ID())
+	if err != nil || len(found) != 1 {
+		t.Errorf("item not saved by transaction")
+	}
+	_, err = store.FindTag("#mytag")
+	if err != nil {
+		t.Errorf("tag not saved by transaction")
+	}
+}
+
+func transactionRollsBack(t *testing.T, store core.Store) {
+	existing := core.NewTask("existing", time.Now())
+	store.Save(existing)
+
+	item := core.NewTask("some data #mytag", time.Now())
+	err := store.Transaction(func(tx core.Store) error {
+		err := tx.SaveTag(core.NewTag("#mytag"))
+		if err != nil {
+			return err
+		}
+		err = tx.Save(item)
+		if err != nil {
+			return err
+		}
+		existing.Do()
+		err = tx.Save(existing)
+		if err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil || err.Error() != "rollback" {
+		t.Fatalf("transaction did not return error, %v", err)
+	}
+
+	_, err = store.FindAll(item.ID())
+	if err != core.ErrNotFound {
+		t.Errorf("item saved despite rollback")
+	}
+	_, err = store.FindTag("#mytag")
+	if err != core.ErrNotFound {
+		t.Errorf("tag saved despite rollback")
+	}
+	found, err := store.FindAll(existing.ID())
+	if err != nil || found[0].Status() != core.WaitingStatus {
+		t.Errorf("update kept despite rollback")
+	}
+}
+
+func transactionNested(t *testing.T, store core.Store) {
+	item := core.NewTask("some data", time.Now())
+	err := store.Transaction(func(tx core.Store) error {
+		return tx.Transaction(func(inner core.Store) error {
+			return inner.Save(item)
+		})
+	})
+	if err != nil {
+		t.Fatalf("transaction failed %v", err)
+	}
+	found, err := store.FindAll(item.ID())
+	if err != nil || len(found) != 1 {
+		t.Errorf("item not saved by nested transaction")
+	}
+}
@@ -0,0 +1,166 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josler/wdid/core"
+	"github.com/josler/wdid/filter"
+)
+
+func trashTests() map[string]storeTest {
+	return map[string]storeTest{
+		"trash":          trash,
+		"trashNotFound":  trashNotFound,
+		"listTrash":      listTrash,
+		"restore":        restore,
+		"restoreIndexes": restoreIndexes,
+		"deleteTrash":    deleteTrash,
+	}
+}
+
+func trash(t *testing.T, store core.Store) {
+	item := core.NewTask("trashed #mytag", time.Now())
+	store.Save(item)
+	err := store.Trash(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if item.TrashedAt().IsZero() {
+		t.Errorf("trashed time not set")
+	}
+
+	_, err = store.FindAll(item.ID())
+	if err != core.ErrNotFound {
+		t.Errorf("trashed item found %v", err)
+	}
+	items, _ := store.ListFilters([]filter.Filter{})
+	if len(items) != 0 {
+		t.Errorf("trashed item listed %v", items)
+	}
+	items, _ = store.ListFilters([]filter.Filter{core.NewTagFilter(store, filter.FilterEq, "#mytag")})
+	if len(items) != 0 {
+		t.Errorf("trashed item listed by tag %v", items)
+	}
+	err = store.Save(item)
+	if err != core.ErrNotFound {
+		t.Errorf("trashed item saved %v", err)
+	}
+
+	trashed, err := store.FindAllTrash(item.ID()[:3])
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID() != item.ID() || trashed[0].Data() != "trashed #mytag" {
+		t.Errorf("trashed item not found %v", trashed)
+	}
+	if !trashed[0].TrashedAt().Equal(item.TrashedAt()) {
+		t.Errorf("wrong trashed time %v", trashed[0].TrashedAt())
+	}
+}
+
+func trashNotFound(t *testing.T, store core.Store) {
+	item := core.NewTask("never saved", time.Now())
+	store.Save(item)
+	store.Delete(item)
+	err := store.Trash(item)
+	if err != core.ErrNotFound {
+		t.Errorf("wrong error %v", err)
+	}
+	_, err = store.FindAllTrash(item.ID())
+	if err != core.ErrNotFound {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+func listTrash(t *testing.T, store core.Store) {
+	first := core.NewTask("first", time.Now())
+	second := core.NewTask("second", time.Now().Add(-time.Hour))
+	store.Save(first)
+	store.Save(second)
+	store.Save(core.NewTask("kept", time.Now()))
+	store.Trash(first)
+	store.Trash(second)
+
+	trashed, err := store.ListTrash()
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if len(trashed) != 2 || trashed[0].Data() != "first" || trashed[1].Data() != "second" {
+		t.Errorf("wrong trash listed %v", trashed)
+	}
+}
+
+func restore(t *testing.T, store core.Store) {
+	item := core.NewTask("restored", time.Now())
+	store.Save(item)
+	store.Trash(item)
+
+	err := store.Restore(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	if !item.TrashedAt().IsZero() {
+		t.Errorf("trashed time not cleared")
+	}
+	found, err := store.FindAll(item.ID())
+	if err != nil || len(found) != 1 || found[0].Data() != "restored" {
+		t.Errorf("restored item not found %v", err)
+	}
+	if len(found) == 1 && !found[0].TrashedAt().IsZero() {
+		t.Errorf("restored item still trashed")
+	}
+	trashed, _ := store.ListTrash()
+	if len(trashed) != 0 {
+		t.Errorf("restored item still in trash %v", trashed)
+	}
+	err = store.Restore(item)
+	if err != core.ErrNotFound {
+		t.Errorf("wrong error %v", err)
+	}
+
+	// the restored item can still be updated
+	found[0].Do()
+	err = store.Save(found[0])
+	if err != nil {
+		t.Errorf("failed to save restored item %v", err)
+	}
+}
+
+func restoreIndexes(t *testing.T, store core.Store) {
+	item := core.NewTask("restored migration #mytag", time.Now())
+	store.Save(item)
+	store.Trash(item)
+	store.Restore(item)
+
+	items, _ := store.ListFilters([]filter.Filter{
+		core.NewTagFilter(store, filter.FilterEq, "#mytag"),
+		core.NewTextFilter(filter.FilterEq, "migration"),
+	})
+	if len(items) != 1 || items[0].ID() != item.ID() {
+		t.Errorf("restored item not indexed %v", items)
+	}
+}
+
+func deleteTrash(t *testing.T, store core.Store) {
+	item := core.NewTask("deleted", time.Now())
+	store.Save(item)
+	store.Trash(item)
+
+	err := store.DeleteTrash(item)
+	if err != nil {
+		t.Fatalf("error %s", err)
+	}
+	trashed, _ := store.ListTrash()
+	if len(trashed) != 0 {
+		t.Errorf("deleted item still in trash %v", trashed)
+	}
+	err = store.Restore(item)
+	if err != core.ErrNotFound {
+		t.Errorf("wrong error %v", err)
+	}
+	err = store.DeleteTrash(item)
+	if err != core.ErrNotFound {
+		t.Errorf("wrong error %v", err)
+	}
+}